@@ -0,0 +1,179 @@
+package stl
+
+import "bytes"
+
+// Color is an 8 bit per channel RGBA color
+type Color struct {
+	R uint8
+	G uint8
+	B uint8
+	A uint8
+}
+
+// Material is the Materialise Magics default material of a Solid
+type Material struct {
+	Diffuse  Color
+	Specular Color
+	Ambient  Color
+}
+
+// ColorFormat is the convention used to store colors in a binary STL.
+// Both store a 15 bit RGB color in each Triangle's AttrByteCnt, but differ in
+// the order of the channels and the meaning of the top bit.
+type ColorFormat int
+
+const (
+	// ColorNone is input without colors
+	ColorNone ColorFormat = iota
+	// ColorVisCAM is the VisCAM and SolidView convention.
+	// The top bit is set when the Triangle has a color, and blue is in the low bits.
+	ColorVisCAM
+	// ColorMagics is the Materialise Magics convention.
+	// The top bit is clear when the Triangle has a color, and red is in the low bits.
+	// Triangles without one use the default color of the Solid, from "COLOR=" in the header.
+	ColorMagics
+)
+
+func (f ColorFormat) String() string {
+	switch f {
+	case ColorVisCAM:
+		return "VisCAM"
+	case ColorMagics:
+		return "Magics"
+	default:
+		return "none"
+	}
+}
+
+// Keys marking the Magics default color and material in a binary header
+const (
+	colorKey    = "COLOR="
+	materialKey = "MATERIAL="
+)
+
+const colorValid = 1 << 15
+
+// Color is the color of the Triangle stored in AttrByteCnt using the convention f.
+// ok is false when the Triangle does not have a color of its own.
+func (t Triangle) Color(f ColorFormat) (c Color, ok bool) {
+	a := t.AttrByteCnt
+	switch f {
+	case ColorVisCAM:
+		if a&colorValid == 0 {
+			return Color{}, false
+		}
+		return Color{R: from5(a >> 10), G: from5(a >> 5), B: from5(a), A: 255}, true
+	case ColorMagics:
+		if a&colorValid != 0 {
+			return Color{}, false
+		}
+		return Color{R: from5(a), G: from5(a >> 5), B: from5(a >> 10), A: 255}, true
+	default:
+		return Color{}, false
+	}
+}
+
+// SetColor stores c in AttrByteCnt using the convention f.
+// Only the top 5 bits of each channel are kept, and alpha is dropped.
+func (t *Triangle) SetColor(f ColorFormat, c Color) {
+	switch f {
+	case ColorVisCAM:
+		t.AttrByteCnt = colorValid | to5(c.R)<<10 | to5(c.G)<<5 | to5(c.B)
+	case ColorMagics:
+		t.AttrByteCnt = to5(c.B)<<10 | to5(c.G)<<5 | to5(c.R)
+	}
+}
+
+// ClearColor marks the Triangle as not having a color of its own using the convention f
+func (t *Triangle) ClearColor(f ColorFormat) {
+	switch f {
+	case ColorVisCAM:
+		t.AttrByteCnt = 0
+	case ColorMagics:
+		t.AttrByteCnt = colorValid
+	}
+}
+
+// FacetColor is the color of Triangle i using the ColorFormat of the Solid.
+// For ColorMagics, a Triangle without its own color has the default Color of the Solid.
+func (s *Solid) FacetColor(i int) (Color, bool) {
+	if c, ok := s.Triangles[i].Color(s.ColorFormat); ok {
+		return c, true
+	}
+	if s.ColorFormat == ColorMagics && s.Color != nil {
+		return *s.Color, true
+	}
+
+	return Color{}, false
+}
+
+// from5 scales the low 5 bits of v to 8 bits
+func from5(v uint16) uint8 {
+	v &= 0x1f
+	return uint8(v<<3 | v>>2)
+}
+
+// to5 scales an 8 bit channel to 5 bits
+func to5(v uint8) uint16 {
+	return uint16(v >> 3)
+}
+
+// parseBinaryHeader splits a raw binary header into its text and any Magics default color and material
+func parseBinaryHeader(raw []byte) (string, *Color, *Material) {
+	raw = append([]byte{}, raw...)
+
+	var color *Color
+	if i := bytes.Index(raw, []byte(colorKey)); i >= 0 && i+len(colorKey)+4 <= len(raw) {
+		v := raw[i+len(colorKey):]
+		color = &Color{R: v[0], G: v[1], B: v[2], A: v[3]}
+		raw = append(raw[:i], raw[i+len(colorKey)+4:]...)
+	}
+
+	var material *Material
+	if i := bytes.Index(raw, []byte(materialKey)); i >= 0 && i+len(materialKey)+12 <= len(raw) {
+		v := raw[i+len(materialKey):]
+		material = &Material{
+			Diffuse:  Color{R: v[0], G: v[1], B: v[2], A: v[3]},
+			Specular: Color{R: v[4], G: v[5], B: v[6], A: v[7]},
+			Ambient:  Color{R: v[8], G: v[9], B: v[10], A: v[11]},
+		}
+		raw = append(raw[:i], raw[i+len(materialKey)+12:]...)
+	}
+
+	return string(bytes.TrimSpace(raw)), color, material
+}
+
+// binaryHeaderText is the header of s with its Magics default color and material in front
+func binaryHeaderText(s *Solid) string {
+	var b bytes.Buffer
+	if s.Color != nil {
+		b.WriteString(colorKey)
+		b.Write([]byte{s.Color.R, s.Color.G, s.Color.B, s.Color.A})
+		b.WriteByte(' ')
+	}
+	if m := s.Material; m != nil {
+		b.WriteString(materialKey)
+		for _, c := range []Color{m.Diffuse, m.Specular, m.Ambient} {
+			b.Write([]byte{c.R, c.G, c.B, c.A})
+		}
+		b.WriteByte(' ')
+	}
+	b.WriteString(s.Header)
+
+	return b.String()
+}
+
+// detectColorFormat is the convention used by a binary input.
+// A Magics header key wins, otherwise any Triangle with the top bit set is taken as VisCAM.
+func detectColorFormat(color *Color, material *Material, tris []Triangle) ColorFormat {
+	if color != nil || material != nil {
+		return ColorMagics
+	}
+	for _, t := range tris {
+		if t.AttrByteCnt&colorValid != 0 {
+			return ColorVisCAM
+		}
+	}
+
+	return ColorNone
+}
@@ -0,0 +1,189 @@
+package stl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTriangle_Color(t *testing.T) {
+	for _, tst := range []struct {
+		name   string
+		format ColorFormat
+		attr   uint16
+		want   Color
+		wantOk bool
+	}{
+		{
+			name:   "VisCAM red",
+			format: ColorVisCAM,
+			attr:   0xfc00,
+			want:   Color{R: 255, A: 255},
+			wantOk: true,
+		},
+		{
+			name:   "VisCAM blue",
+			format: ColorVisCAM,
+			attr:   0x801f,
+			want:   Color{B: 255, A: 255},
+			wantOk: true,
+		},
+		{
+			name:   "VisCAM without color",
+			format: ColorVisCAM,
+			attr:   0x001f,
+		},
+		{
+			name:   "Magics red",
+			format: ColorMagics,
+			attr:   0x001f,
+			want:   Color{R: 255, A: 255},
+			wantOk: true,
+		},
+		{
+			name:   "Magics mixed",
+			format: ColorMagics,
+			attr:   0x10 | 0x08<<5 | 0x01<<10,
+			want:   Color{R: 132, G: 66, B: 8, A: 255},
+			wantOk: true,
+		},
+		{
+			name:   "Magics default color",
+			format: ColorMagics,
+			attr:   0x801f,
+		},
+		{
+			name:   "no color format",
+			format: ColorNone,
+			attr:   0xfc00,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			got, ok := Triangle{AttrByteCnt: tst.attr}.Color(tst.format)
+			if got != tst.want || ok != tst.wantOk {
+				t.Errorf("got %+v, %t; want %+v, %t", got, ok, tst.want, tst.wantOk)
+			}
+		})
+	}
+}
+func TestTriangle_SetColor(t *testing.T) {
+	c := Color{R: 255, G: 66, B: 8, A: 255}
+	for _, f := range []ColorFormat{ColorVisCAM, ColorMagics} {
+		f := f
+		t.Run(f.String(), func(t *testing.T) {
+			t.Parallel()
+			var tri Triangle
+			tri.SetColor(f, c)
+			if got, ok := tri.Color(f); !ok || got != c {
+				t.Errorf("got %+v, %t; want %+v, true", got, ok, c)
+			}
+
+			tri.ClearColor(f)
+			if got, ok := tri.Color(f); ok {
+				t.Errorf("got %+v after ClearColor; want no color", got)
+			}
+		})
+	}
+}
+func TestSolid_FacetColor(t *testing.T) {
+	def := Color{R: 10, G: 20, B: 30, A: 40}
+	s := Solid{ColorFormat: ColorMagics, Color: &def, Triangles: make([]Triangle, 2)}
+	s.Triangles[0].SetColor(ColorMagics, Color{R: 255, A: 255})
+	s.Triangles[1].ClearColor(ColorMagics)
+
+	if got, ok := s.FacetColor(0); !ok || got != (Color{R: 255, A: 255}) {
+		t.Errorf("got %+v, %t for own color; want red", got, ok)
+	}
+	if got, ok := s.FacetColor(1); !ok || got != def {
+		t.Errorf("got %+v, %t for default color; want %+v", got, ok, def)
+	}
+}
+func TestSolid_ColorRoundTrip(t *testing.T) {
+	for _, tst := range []struct {
+		name string
+		in   Solid
+	}{
+		{
+			name: "VisCAM",
+			in:   Solid{Header: "viscam", ColorFormat: ColorVisCAM},
+		},
+		{
+			name: "Magics",
+			in: Solid{
+				Header:      "magics",
+				ColorFormat: ColorMagics,
+				Color:       &Color{R: 1, G: 2, B: 3, A: 4},
+				Material: &Material{
+					Diffuse:  Color{R: 5, G: 6, B: 7, A: 8},
+					Specular: Color{R: 9, G: 10, B: 11, A: 12},
+					Ambient:  Color{R: 13, G: 14, B: 15, A: 16},
+				},
+			},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			in := tst.in
+			in.Triangles = testSolid().Triangles
+			in.TriangleCount = uint32(len(in.Triangles))
+			in.Triangles[0].SetColor(in.ColorFormat, Color{R: 255, A: 255})
+			in.Triangles[1].SetColor(in.ColorFormat, Color{G: 255, A: 255})
+			in.Triangles[2].ClearColor(in.ColorFormat)
+
+			buf := &bytes.Buffer{}
+			if err := in.ToBinary(buf); err != nil {
+				t.Fatalf("could not write solid: %v", err)
+			}
+			raw := append([]byte{}, buf.Bytes()...)
+
+			got, err := From(buf)
+			if err != nil {
+				t.Fatalf("could not read solid: %v", err)
+			}
+			if got.ColorFormat != in.ColorFormat {
+				t.Errorf("got %s format; want %s", got.ColorFormat, in.ColorFormat)
+			}
+			if h := strings.TrimRight(got.Header, "\x00"); h != in.Header {
+				t.Errorf("got header %q; want %q", got.Header, in.Header)
+			}
+			if (got.Color == nil) != (in.Color == nil) || got.Color != nil && *got.Color != *in.Color {
+				t.Errorf("got color %v; want %v", got.Color, in.Color)
+			}
+			if (got.Material == nil) != (in.Material == nil) || got.Material != nil && *got.Material != *in.Material {
+				t.Errorf("got material %v; want %v", got.Material, in.Material)
+			}
+			for i := range in.Triangles {
+				if got.Triangles[i].AttrByteCnt != in.Triangles[i].AttrByteCnt {
+					t.Errorf("got attribute %#x for triangle %d; want %#x", got.Triangles[i].AttrByteCnt, i, in.Triangles[i].AttrByteCnt)
+				}
+			}
+
+			// Writing again gives the same bytes
+			again := &bytes.Buffer{}
+			if err := got.ToBinary(again); err != nil {
+				t.Fatalf("could not write solid: %v", err)
+			}
+			if !bytes.Equal(again.Bytes(), raw) {
+				t.Errorf("got different bytes after round trip")
+			}
+		})
+	}
+}
+func Test_parseBinaryHeader(t *testing.T) {
+	raw := make([]byte, 80)
+	copy(raw, "part COLOR=\x01\x02\x03\x04 made by me")
+
+	header, color, material := parseBinaryHeader(raw)
+	if want := "part  made by me"; strings.TrimRight(header, "\x00") != want {
+		t.Errorf("got header %q; want %q", header, want)
+	}
+	if color == nil || *color != (Color{R: 1, G: 2, B: 3, A: 4}) {
+		t.Errorf("got color %v; want {1 2 3 4}", color)
+	}
+	if material != nil {
+		t.Errorf("got material %v; want nil", material)
+	}
+}
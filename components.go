@@ -0,0 +1,225 @@
+package stl
+
+import "math"
+
+// Shell is a connected group of Triangles and where it sits among the other shells of a Solid
+type Shell struct {
+	// Triangles are indexes into Solid.Triangles
+	Triangles []int
+	// Closed is set when every edge of the shell is shared by exactly two of its Triangles
+	Closed bool
+	// Volume is the signed volume enclosed by the shell
+	Volume float64
+	// Parent is the index of the smallest closed shell enclosing this one, or -1
+	Parent int
+	// Depth is the number of closed shells enclosing this one
+	Depth int
+}
+
+// Void reports whether the shell is inside an odd number of others, so it is a cavity
+// in the shell around it rather than a body of its own
+func (sh Shell) Void() bool {
+	return sh.Depth%2 == 1
+}
+
+// Outward reports whether the shell is closed and faces the right way for its depth:
+// outward with a positive Volume for a body, and inward with a negative Volume for a Void
+func (sh Shell) Outward() bool {
+	return sh.Closed && sh.Volume != 0 && (sh.Volume > 0) != sh.Void()
+}
+
+// Components splits the Solid into one Solid for each group of Triangles connected by shared edges,
+// in order of their first Triangle.  Each keeps the Header, colors and material of the Solid.
+// Vertices are only shared when they are identical.  Weld or Repair first to join near misses.
+func (s *Solid) Components() []Solid {
+	mesh, _ := s.Indexed(0)
+	return s.parts(edgeComponents(mesh, make([]bool, len(mesh.Faces))))
+}
+
+// VertexComponents is like Components, but Triangles touching at a single vertex are also connected
+func (s *Solid) VertexComponents() []Solid {
+	mesh, _ := s.Indexed(0)
+
+	uf := newUnionFind(len(mesh.Faces))
+	first := make(map[int]int, len(mesh.Vertices))
+	for i, f := range mesh.Faces {
+		for _, v := range f.Vertices {
+			if j, ok := first[v]; ok {
+				uf.union(j, i)
+			} else {
+				first[v] = i
+			}
+		}
+	}
+
+	return s.parts(unionGroups(uf, make([]bool, len(mesh.Faces))))
+}
+func (s *Solid) parts(groups [][]int) []Solid {
+	parts := make([]Solid, len(groups))
+	for i, g := range groups {
+		part := *s
+		part.Triangles = make([]Triangle, len(g))
+		for j, t := range g {
+			part.Triangles[j] = s.Triangles[t]
+		}
+		part.TriangleCount = uint32(len(g))
+		parts[i] = part
+	}
+
+	return parts
+}
+
+// Shells finds the groups of Triangles connected by shared edges and how they nest.
+// A closed shell inside another is a void, which should face inward so that its
+// negative volume is taken from the shell around it by Volume and MassProperties.
+// Triangles that collapse to a line are left out.
+func (s *Solid) Shells() []Shell {
+	mesh, _ := s.Indexed(0)
+	return meshShells(mesh, edgeComponents(mesh, collapsedFaces(mesh)))
+}
+
+// meshShells measures each group of faces and finds which closed groups enclose it
+func meshShells(mesh IndexedMesh, groups [][]int) []Shell {
+	shells := make([]Shell, len(groups))
+	for i, g := range groups {
+		shells[i] = Shell{
+			Triangles: g,
+			Closed:    closedGroup(mesh, g),
+			Volume:    groupVolume(mesh, g),
+			Parent:    -1,
+		}
+	}
+
+	for i := range shells {
+		p := mesh.Vertices[mesh.Faces[shells[i].Triangles[0]].Vertices[0]].Vec3()
+		for j, outer := range shells {
+			if i == j || !outer.Closed || !insideGroup(mesh, outer.Triangles, p) {
+				continue
+			}
+
+			shells[i].Depth++
+			if k := shells[i].Parent; k < 0 || math.Abs(outer.Volume) < math.Abs(shells[k].Volume) {
+				shells[i].Parent = j
+			}
+		}
+	}
+
+	return shells
+}
+
+// insideGroup reports whether p is inside the closed group of faces, by counting
+// how many faces a ray from p crosses
+func insideGroup(mesh IndexedMesh, group []int, p Vec3) bool {
+	// An irregular direction is unlikely to graze an edge or vertex
+	dir := Vec3{X: 1, Y: math.Sqrt2 / 1000, Z: math.Sqrt(3) / 1000}
+
+	inside := false
+	for _, f := range group {
+		v := mesh.Faces[f].Vertices
+		if rayHits(p, dir, mesh.Vertices[v[0]].Vec3(), mesh.Vertices[v[1]].Vec3(), mesh.Vertices[v[2]].Vec3()) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// rayHits reports whether the ray from p along dir crosses the triangle a, b, c,
+// with the Möller-Trumbore test
+func rayHits(p, dir, a, b, c Vec3) bool {
+	e1, e2 := b.Sub(a), c.Sub(a)
+	h := dir.Cross(e2)
+	det := e1.Dot(h)
+	if det == 0 {
+		return false
+	}
+
+	s := p.Sub(a)
+	u := s.Dot(h) / det
+	if u < 0 || u > 1 {
+		return false
+	}
+	q := s.Cross(e1)
+	v := dir.Dot(q) / det
+	if v < 0 || u+v > 1 {
+		return false
+	}
+
+	return e2.Dot(q)/det > 0
+}
+
+// collapsedFaces marks faces with two corners welded to the same vertex
+func collapsedFaces(mesh IndexedMesh) []bool {
+	skip := make([]bool, len(mesh.Faces))
+	for i, f := range mesh.Faces {
+		v := f.Vertices
+		skip[i] = v[0] == v[1] || v[1] == v[2] || v[2] == v[0]
+	}
+
+	return skip
+}
+
+// edgeComponents groups the faces not skipped that are connected through shared edges
+func edgeComponents(mesh IndexedMesh, skip []bool) [][]int {
+	uf := newUnionFind(len(mesh.Faces))
+	for _, e := range meshEdges(mesh, skip) {
+		for _, u := range e.uses[1:] {
+			uf.union(e.uses[0].face, u.face)
+		}
+	}
+
+	return unionGroups(uf, skip)
+}
+
+// unionGroups lists the members not skipped of each set, in order of their first member
+func unionGroups(uf unionFind, skip []bool) [][]int {
+	var groups [][]int
+	index := make(map[int]int)
+	for i := range uf {
+		if skip[i] {
+			continue
+		}
+
+		root := uf.find(i)
+		g, ok := index[root]
+		if !ok {
+			g = len(groups)
+			index[root] = g
+			groups = append(groups, nil)
+		}
+		groups[g] = append(groups[g], i)
+	}
+
+	return groups
+}
+
+// closedGroup reports whether every edge of the faces in group is shared by exactly two of them
+func closedGroup(mesh IndexedMesh, group []int) bool {
+	uses := make(map[[2]int]int, 3*len(group)/2)
+	for _, f := range group {
+		v := mesh.Faces[f].Vertices
+		for j := 0; j < 3; j++ {
+			a, b := v[j], v[(j+1)%3]
+			uses[[2]int{min(a, b), max(a, b)}]++
+		}
+	}
+	for _, n := range uses {
+		if n != 2 {
+			return false
+		}
+	}
+
+	return len(group) > 0
+}
+
+// groupVolume is the signed volume enclosed by the faces in group
+func groupVolume(mesh IndexedMesh, group []int) float64 {
+	vol := 0.0
+	for _, f := range group {
+		v := mesh.Faces[f].Vertices
+		a, b, c := mesh.Vertices[v[0]].Vec3(), mesh.Vertices[v[1]].Vec3(), mesh.Vertices[v[2]].Vec3()
+		vol += a.Dot(b.Cross(c))
+	}
+
+	return vol / 6
+}
@@ -0,0 +1,156 @@
+package stl
+
+import "testing"
+
+// boxWithVoid is a 4x4x4 box with a 2x2x2 cavity in the middle, wound inward unless outward is set
+func boxWithVoid(outward bool) Solid {
+	s := testBox(4, 4, 4)
+	void := testBox(2, 2, 2)
+	void.Transform(Translate(1, 1, 1))
+	if !outward {
+		for i := range void.Triangles {
+			v := &void.Triangles[i].Vertices
+			v[1], v[2] = v[2], v[1]
+		}
+		void.RecomputeNormals()
+	}
+	s.Triangles = append(s.Triangles, void.Triangles...)
+	s.TriangleCount = uint32(len(s.Triangles))
+
+	return s
+}
+func TestSolid_Components(t *testing.T) {
+	apart := testBox(1, 1, 1)
+	other := testBox(1, 1, 1)
+	other.Transform(Translate(3, 0, 0))
+	apart.Triangles = append(apart.Triangles, other.Triangles...)
+
+	corner := testBox(1, 1, 1)
+	other = testBox(1, 1, 1)
+	other.Transform(Translate(1, 1, 1))
+	corner.Triangles = append(corner.Triangles, other.Triangles...)
+
+	for _, tst := range []struct {
+		name         string
+		in           Solid
+		wantEdge     []int
+		wantByVertex []int
+	}{
+		{
+			name:         "one box",
+			in:           testBox(1, 2, 3),
+			wantEdge:     []int{12},
+			wantByVertex: []int{12},
+		},
+		{
+			name:         "apart",
+			in:           apart,
+			wantEdge:     []int{12, 12},
+			wantByVertex: []int{12, 12},
+		},
+		{
+			name:         "touching corners",
+			in:           corner,
+			wantEdge:     []int{12, 12},
+			wantByVertex: []int{24},
+		},
+		{
+			name:         "empty",
+			in:           Solid{},
+			wantEdge:     []int{},
+			wantByVertex: []int{},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			for _, c := range []struct {
+				parts []Solid
+				want  []int
+			}{
+				{tst.in.Components(), tst.wantEdge},
+				{tst.in.VertexComponents(), tst.wantByVertex},
+			} {
+				if len(c.parts) != len(c.want) {
+					t.Fatalf("got %d components; want %d", len(c.parts), len(c.want))
+				}
+				for i, p := range c.parts {
+					if len(p.Triangles) != c.want[i] || p.TriangleCount != uint32(c.want[i]) || p.Header != tst.in.Header {
+						t.Errorf("got component %d with %d triangles, count %d, header %q; want %d, %q",
+							i, len(p.Triangles), p.TriangleCount, p.Header, c.want[i], tst.in.Header)
+					}
+				}
+			}
+		})
+	}
+}
+func TestSolid_Shells(t *testing.T) {
+	// A 1x1x1 island inside the void
+	island := boxWithVoid(false)
+	inner := testBox(1, 1, 1)
+	inner.Transform(Translate(1.5, 1.5, 1.5))
+	island.Triangles = append(island.Triangles, inner.Triangles...)
+
+	for _, tst := range []struct {
+		name        string
+		in          Solid
+		wantParents []int
+		wantOutward []bool
+		wantVolume  float64
+		wantOK      bool
+	}{
+		{
+			name:        "box",
+			in:          testBox(1, 2, 3),
+			wantParents: []int{-1},
+			wantOutward: []bool{true},
+			wantVolume:  6,
+			wantOK:      true,
+		},
+		{
+			name:        "void",
+			in:          boxWithVoid(false),
+			wantParents: []int{-1, 0},
+			wantOutward: []bool{true, true},
+			wantVolume:  56,
+			wantOK:      true,
+		},
+		{
+			name:        "void facing out",
+			in:          boxWithVoid(true),
+			wantParents: []int{-1, 0},
+			wantOutward: []bool{true, false},
+			wantVolume:  72,
+		},
+		{
+			name:        "island in void",
+			in:          island,
+			wantParents: []int{-1, 0, 1},
+			wantOutward: []bool{true, true, true},
+			wantVolume:  57,
+			wantOK:      true,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			shells := tst.in.Shells()
+			if len(shells) != len(tst.wantParents) {
+				t.Fatalf("got %d shells; want %d", len(shells), len(tst.wantParents))
+			}
+			for i, sh := range shells {
+				if sh.Parent != tst.wantParents[i] || sh.Depth != i || sh.Void() != (i%2 == 1) || sh.Outward() != tst.wantOutward[i] {
+					t.Errorf("got shell %d parent %d, depth %d, void %t, outward %t; want %d, %d, %t, %t",
+						i, sh.Parent, sh.Depth, sh.Void(), sh.Outward(), tst.wantParents[i], i, i%2 == 1, tst.wantOutward[i])
+				}
+			}
+
+			if v := tst.in.Volume(); !near(v, tst.wantVolume) {
+				t.Errorf("got volume %v; want %v", v, tst.wantVolume)
+			}
+			if r := tst.in.Validate(0); r.OK() != tst.wantOK {
+				t.Errorf("got ok %t; want %t", r.OK(), tst.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,196 @@
+package stl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"iter"
+)
+
+// Decoder reads Triangles from an input one at a time.
+// Unlike From, it only holds a few kilobytes of the input in memory at once,
+// which makes it suitable for inputs too large to load into a Solid.
+type Decoder struct {
+	// StrictCount makes binary input that does not hold exactly the declared
+	// number of Triangles an ErrCountMismatch.  Otherwise every Triangle present is read.
+	StrictCount bool
+	// RecomputeNormals replaces the normal of every Triangle read with the one
+	// given by its winding.  See Solid.RecomputeNormals
+	RecomputeNormals bool
+
+	br      *bufio.Reader
+	format  Format
+	header  string
+	count   uint32
+	scanner *bufio.Scanner
+	pos     *position
+	facet   int
+	pending []Triangle
+	bin     []byte
+	err     error
+}
+
+// Bytes of ASCII input parsed at a time by a Decoder
+const decoderChunkSize = 4096
+
+// NewDecoder reads the header of the input and returns a Decoder positioned
+// at the first Triangle.
+// It handles both ASCII and binary formats.
+func NewDecoder(r io.Reader) (*Decoder, error) {
+	d := &Decoder{br: bufio.NewReader(r)}
+
+	format, err := detectFormat(d.br, inputSize(r))
+	if err != nil {
+		return nil, err
+	}
+	d.format = format
+
+	if format == FormatASCII {
+		header, n, err := extractASCIIHeader(d.br)
+		if err != nil {
+			return nil, err
+		}
+		d.header = header
+
+		d.pos = &position{offset: int64(n), line: 2, countLines: true}
+		d.scanner = bufio.NewScanner(d.br)
+		d.scanner.Split(d.pos.track(splitFacetsASCII(decoderChunkSize)))
+
+		return d, nil
+	}
+
+	raw, err := extractBinaryHeader(d.br)
+	if err != nil {
+		return nil, err
+	}
+	d.header, _, _ = parseBinaryHeader(raw)
+	if d.count, err = extractBinaryTriangleCount(d.br); err != nil {
+		return nil, err
+	}
+	d.bin = make([]byte, 50)
+
+	return d, nil
+}
+
+// Header is the header of a binary input or the name of an ASCII solid
+func (d *Decoder) Header() string {
+	return d.header
+}
+
+// Format is the detected format of the input
+func (d *Decoder) Format() Format {
+	return d.format
+}
+
+// TriangleCount is the number of Triangles declared in a binary header.
+// ASCII input does not declare a count, so ok is false.
+func (d *Decoder) TriangleCount() (count uint32, ok bool) {
+	return d.count, d.format == FormatBinary
+}
+
+// Next returns the next Triangle in the input.
+// It returns io.EOF when there are no more Triangles.
+// See Decoder.StrictCount for binary input not holding the declared number of Triangles.
+// Any other error is returned for all subsequent calls.
+func (d *Decoder) Next() (Triangle, error) {
+	if d.err != nil {
+		return Triangle{}, d.err
+	}
+
+	var t Triangle
+	if d.format == FormatASCII {
+		t, d.err = d.nextASCII()
+	} else {
+		t, d.err = d.nextBinary()
+	}
+	if d.err == nil && d.RecomputeNormals {
+		t.Normal = t.ComputeNormal()
+	}
+
+	return t, d.err
+}
+
+// NextBatch fills tris with the next Triangles in the input and returns how
+// many were read.
+// It returns io.EOF, along with any final Triangles, when the input is exhausted.
+func (d *Decoder) NextBatch(tris []Triangle) (int, error) {
+	for n := range tris {
+		t, err := d.Next()
+		if err != nil {
+			return n, err
+		}
+		tris[n] = t
+	}
+
+	return len(tris), nil
+}
+
+// All returns an iterator over the remaining Triangles in the input.
+// Iteration stops after the first error is yielded.
+func (d *Decoder) All() iter.Seq2[Triangle, error] {
+	return func(yield func(Triangle, error) bool) {
+		for {
+			t, err := d.Next()
+			if err == io.EOF {
+				return
+			}
+			if !yield(t, err) || err != nil {
+				return
+			}
+		}
+	}
+}
+func (d *Decoder) nextASCII() (Triangle, error) {
+	// Parse the next chunk once the last one is used up
+	for len(d.pending) == 0 {
+		if !d.scanner.Scan() {
+			if d.scanner.Err() != nil {
+				return Triangle{}, d.pos.errorAt(FormatASCII, d.facet, d.scanner.Err())
+			}
+			return Triangle{}, io.EOF
+		}
+
+		tris, _, err := trianglesFromASCII(d.scanner.Bytes(), d.pos.tokenLast, asciiRules{})
+		if err != nil {
+			return Triangle{}, err.rebase(d.pos.tokenLine, d.facet, d.pos.tokenOffset)
+		}
+		d.pending = tris
+		d.facet += len(tris)
+	}
+
+	t := d.pending[0]
+	d.pending = d.pending[1:]
+
+	return t, nil
+}
+func (d *Decoder) nextBinary() (Triangle, error) {
+	// Nothing may follow the declared triangles
+	if d.StrictCount && uint32(d.facet) == d.count {
+		_, err := d.br.Peek(1)
+		if err == io.EOF {
+			return Triangle{}, io.EOF
+		}
+		if err == nil {
+			err = fmt.Errorf("%w: data after %d triangles", ErrCountMismatch, d.count)
+		}
+		return Triangle{}, &ParseError{Format: FormatBinary, Facet: -1, Offset: 84 + 50*int64(d.facet), Err: err}
+	}
+
+	_, err := io.ReadFull(d.br, d.bin)
+	if err == io.EOF {
+		if !d.StrictCount {
+			return Triangle{}, io.EOF
+		}
+		err = fmt.Errorf("%w: header declares %d triangles, data holds %d", ErrCountMismatch, d.count, d.facet)
+		return Triangle{}, &ParseError{Format: FormatBinary, Facet: -1, Offset: 80, Err: err}
+	}
+	if err != nil {
+		if err == io.ErrUnexpectedEOF {
+			err = fmt.Errorf("%w: partial triangle", ErrTruncated)
+		}
+		return Triangle{}, &ParseError{Format: FormatBinary, Facet: d.facet, Offset: 84 + 50*int64(d.facet), Err: err}
+	}
+	d.facet++
+
+	return triangleFromBinary(d.bin), nil
+}
@@ -0,0 +1,190 @@
+package stl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func testSolid() Solid {
+	return Solid{
+		Header:        "test",
+		TriangleCount: 3,
+		Triangles: []Triangle{
+			{
+				Normal:   UnitVector{Ni: 0, Nj: 0, Nk: 1},
+				Vertices: [3]Coordinate{{X: 0, Y: 0, Z: 0}, {X: 1, Y: 0, Z: 0}, {X: 0, Y: 1, Z: 0}},
+			},
+			{
+				Normal:   UnitVector{Ni: 0, Nj: 0, Nk: -1},
+				Vertices: [3]Coordinate{{X: 0, Y: 0, Z: 0}, {X: 0, Y: 1, Z: 0}, {X: 1, Y: 0, Z: 0}},
+			},
+			{
+				Normal:   UnitVector{Ni: 1, Nj: 0, Nk: 0},
+				Vertices: [3]Coordinate{{X: 2, Y: 0, Z: 0}, {X: 2, Y: 1, Z: 0}, {X: 2, Y: 0, Z: 1.5}},
+			},
+		},
+	}
+}
+func TestDecoder(t *testing.T) {
+	solid := testSolid()
+
+	for _, tst := range []struct {
+		name   string
+		to     func(io.Writer) error
+		format Format
+	}{
+		{
+			name:   "ASCII",
+			to:     solid.ToASCII,
+			format: FormatASCII,
+		},
+		{
+			name:   "binary",
+			to:     solid.ToBinary,
+			format: FormatBinary,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			if err := tst.to(buf); err != nil {
+				t.Fatalf("could not write solid: %v", err)
+			}
+
+			d, err := NewDecoder(buf)
+			if err != nil {
+				t.Fatalf("could not create decoder: %v", err)
+			}
+			if d.Format() != tst.format {
+				t.Errorf("got format %s; want %s", d.Format(), tst.format)
+			}
+			// Binary headers keep their padding
+			if strings.TrimRight(d.Header(), "\x00") != solid.Header {
+				t.Errorf("got header %q; want %q", d.Header(), solid.Header)
+			}
+
+			i := 0
+			for tri, err := range d.All() {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				if tri != solid.Triangles[i] {
+					t.Errorf("got %+v for triangle %d; want %+v", tri, i, solid.Triangles[i])
+				}
+				i++
+			}
+			if i != len(solid.Triangles) {
+				t.Errorf("got %d triangles; want %d", i, len(solid.Triangles))
+			}
+			if _, err := d.Next(); err != io.EOF {
+				t.Errorf("got %v after last triangle; want io.EOF", err)
+			}
+		})
+	}
+}
+func TestDecoder_NextBatch(t *testing.T) {
+	solid := testSolid()
+	buf := &bytes.Buffer{}
+	if err := solid.ToBinary(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	d, err := NewDecoder(buf)
+	if err != nil {
+		t.Fatalf("could not create decoder: %v", err)
+	}
+	if cnt, ok := d.TriangleCount(); !ok || cnt != 3 {
+		t.Errorf("got count %d, %t; want 3, true", cnt, ok)
+	}
+
+	batch := make([]Triangle, 2)
+	if n, err := d.NextBatch(batch); n != 2 || err != nil {
+		t.Errorf("got %d, %v; want 2, nil", n, err)
+	}
+	if n, err := d.NextBatch(batch); n != 1 || err != io.EOF {
+		t.Errorf("got %d, %v; want 1, io.EOF", n, err)
+	}
+	if batch[0] != solid.Triangles[2] {
+		t.Errorf("got %+v; want %+v", batch[0], solid.Triangles[2])
+	}
+}
+func TestDecoder_Truncated(t *testing.T) {
+	solid := testSolid()
+	buf := &bytes.Buffer{}
+	if err := solid.ToBinary(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	buf.Truncate(buf.Len() - 1)
+
+	d, err := NewDecoder(buf)
+	if err != nil {
+		t.Fatalf("could not create decoder: %v", err)
+	}
+	n, err := d.NextBatch(make([]Triangle, 3))
+	if n != 2 || err == nil || err == io.EOF {
+		t.Errorf("got %d, %v; want 2 and a read error", n, err)
+	}
+}
+func TestDecoder_CountMismatch(t *testing.T) {
+	solid := testSolid()
+	buf := &bytes.Buffer{}
+	if err := solid.ToBinary(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	for _, tst := range []struct {
+		name    string
+		count   uint32
+		strict  bool
+		want    int
+		wantErr error
+	}{
+		{
+			name:    "fewer triangles than declared",
+			count:   4,
+			want:    3,
+			wantErr: io.EOF,
+		},
+		{
+			name:    "more triangles than declared",
+			count:   2,
+			want:    3,
+			wantErr: io.EOF,
+		},
+		{
+			name:    "fewer triangles than declared, strict",
+			count:   4,
+			strict:  true,
+			want:    3,
+			wantErr: ErrCountMismatch,
+		},
+		{
+			name:    "more triangles than declared, strict",
+			count:   2,
+			strict:  true,
+			want:    2,
+			wantErr: ErrCountMismatch,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			in := append([]byte{}, buf.Bytes()...)
+			copy(in[80:84], triCountBinary(tst.count))
+
+			d, err := NewDecoder(bytes.NewReader(in))
+			if err != nil {
+				t.Fatalf("could not create decoder: %v", err)
+			}
+			d.StrictCount = tst.strict
+			n, err := d.NextBatch(make([]Triangle, 4))
+			if n != tst.want || !errors.Is(err, tst.wantErr) {
+				t.Errorf("got %d, %v; want %d and %v", n, err, tst.want, tst.wantErr)
+			}
+		})
+	}
+}
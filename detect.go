@@ -0,0 +1,125 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Number of bytes inspected to detect the format
+const detectLen = 512
+
+// DetectFormat reports whether the input is ASCII or binary.
+// If r is a *bufio.Reader only Peek is used, so nothing is consumed.
+// If r is an io.Seeker it is returned to its current position.
+// Otherwise up to 512 bytes are consumed from r.
+func DetectFormat(r io.Reader) (Format, error) {
+	if br, ok := r.(*bufio.Reader); ok {
+		return detectFormat(br, -1)
+	}
+
+	size := inputSize(r)
+	if rs, ok := r.(io.ReadSeeker); ok {
+		pos, err := rs.Seek(0, io.SeekCurrent)
+		if err == nil {
+			defer rs.Seek(pos, io.SeekStart)
+		}
+	}
+
+	return detectFormat(bufio.NewReaderSize(r, detectLen), size)
+}
+
+// detectFormat inspects the start of the input without consuming it.
+// size is the number of bytes in the input, or -1 if it is not known.
+//
+// Binary files are allowed to start with "solid", and many exporters write
+// exactly that, so the prefix alone is not enough.  In order:
+//   - If the size matches 84 + 50 × the declared triangle count, it is binary.
+//   - If it does not start with "solid" and whitespace, it is binary.
+//   - If it contains bytes that never appear in text, it is binary.
+//   - Otherwise it is ASCII.
+func detectFormat(br *bufio.Reader, size int64) (Format, error) {
+	peek, err := br.Peek(min(detectLen, br.Size()))
+	if errors.Is(err, ErrTooLarge) {
+		return FormatUnknown, err
+	}
+	if len(peek) == 0 && err != io.EOF {
+		return FormatUnknown, fmt.Errorf("error reading input: %w", err)
+	}
+	if len(peek) == 0 {
+		return FormatUnknown, ErrEmpty
+	}
+
+	if size >= 84 && len(peek) >= 84 {
+		triCount := int64(binary.LittleEndian.Uint32(peek[80:84]))
+		if 84+50*triCount == size {
+			return FormatBinary, nil
+		}
+	}
+
+	if !hasSolidPrefix(peek) {
+		return FormatBinary, nil
+	}
+
+	if !isText(peek) {
+		return FormatBinary, nil
+	}
+
+	return FormatASCII, nil
+}
+
+// hasSolidPrefix reports whether b starts with the "solid" keyword.
+// The name after it is optional, so it may be followed by any whitespace or nothing at all.
+func hasSolidPrefix(b []byte) bool {
+	if len(b) < 5 || !bytes.EqualFold(b[:5], []byte("solid")) {
+		return false
+	}
+
+	return len(b) == 5 || isSpace(b[5])
+}
+
+// isText reports whether b only has bytes found in an ASCII STL.
+// Bytes above 0x7f are allowed as names may be UTF-8.
+func isText(b []byte) bool {
+	for _, c := range b {
+		if c < 0x20 && !isSpace(c) || c == 0x7f {
+			return false
+		}
+	}
+
+	return true
+}
+func isSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\r', '\v', '\f':
+		return true
+	}
+
+	return false
+}
+
+// inputSize is the number of unread bytes in r, or -1 if it cannot be found without reading.
+func inputSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case io.Seeker:
+		pos, err := v.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return -1
+		}
+		end, err := v.Seek(0, io.SeekEnd)
+		if err != nil {
+			return -1
+		}
+		if _, err := v.Seek(pos, io.SeekStart); err != nil {
+			return -1
+		}
+		return end - pos
+	default:
+		return -1
+	}
+}
@@ -0,0 +1,121 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectFormat(t *testing.T) {
+	solid := testSolid()
+	bin := &bytes.Buffer{}
+	if err := solid.ToBinary(bin); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	// Binary header starting with "solid", as written by some CAD exporters
+	solidHeader := append([]byte{}, bin.Bytes()...)
+	copy(solidHeader, "solid exported part")
+
+	// Same, with a printable triangle count that does not match the size.  The NUL
+	// padding of the header still shows it is not text.
+	printable := append([]byte{}, solidHeader...)
+	copy(printable[80:84], "AAAA")
+
+	for _, tst := range []struct {
+		name string
+		in   io.Reader
+		want Format
+	}{
+		{
+			name: "ASCII",
+			in:   strings.NewReader("solid cube\n facet normal 0 0 1\n"),
+			want: FormatASCII,
+		},
+		{
+			name: "ASCII without a name",
+			in:   strings.NewReader("solid\n facet normal 0 0 1\n"),
+			want: FormatASCII,
+		},
+		{
+			name: "ASCII with a tab",
+			in:   strings.NewReader("solid\tcube\r\n facet normal 0 0 1\r\n"),
+			want: FormatASCII,
+		},
+		{
+			name: "ASCII upper case",
+			in:   strings.NewReader("SOLID cube\n"),
+			want: FormatASCII,
+		},
+		{
+			name: "only the keyword",
+			in:   strings.NewReader("solid"),
+			want: FormatASCII,
+		},
+		{
+			name: "binary",
+			in:   bytes.NewReader(bin.Bytes()),
+			want: FormatBinary,
+		},
+		{
+			name: "binary starting with solid",
+			in:   bytes.NewReader(solidHeader),
+			want: FormatBinary,
+		},
+		{
+			name: "binary starting with solid, size unknown",
+			in:   io.MultiReader(bytes.NewReader(solidHeader)),
+			want: FormatBinary,
+		},
+		{
+			name: "binary starting with solid, printable count",
+			in:   bytes.NewReader(printable[:84]),
+			want: FormatBinary,
+		},
+		{
+			name: "solidworks, not solid",
+			in:   strings.NewReader("solidworks part\n"),
+			want: FormatBinary,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := DetectFormat(tst.in)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != tst.want {
+				t.Errorf("got %s; want %s", got, tst.want)
+			}
+		})
+	}
+}
+func TestDetectFormat_Empty(t *testing.T) {
+	if _, err := DetectFormat(strings.NewReader("")); err == nil {
+		t.Errorf("got no error; want an error")
+	}
+}
+func TestDetectFormat_DoesNotConsume(t *testing.T) {
+	in := "solid cube\nendsolid cube\n"
+
+	// Seekers are returned to where they were
+	sr := strings.NewReader(in)
+	if _, err := DetectFormat(sr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sr.Len() != len(in) {
+		t.Errorf("got %d unread bytes; want %d", sr.Len(), len(in))
+	}
+
+	// Buffered readers are only peeked
+	br := bufio.NewReader(io.MultiReader(strings.NewReader(in)))
+	if _, err := DetectFormat(br); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if rest, _ := io.ReadAll(br); string(rest) != in {
+		t.Errorf("got %q; want %q", rest, in)
+	}
+}
@@ -0,0 +1,165 @@
+package stl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+)
+
+// Encoder writes Triangles to an output one at a time.
+// Unlike Solid.ToASCII and Solid.ToBinary, it never needs the whole mesh in memory.
+// Close must be called to finish the output.
+type Encoder struct {
+	// RecomputeNormals replaces the normal of every Triangle written with the one
+	// given by its winding.  See Solid.RecomputeNormals
+	RecomputeNormals bool
+
+	w        io.Writer
+	bw       *bufio.Writer
+	format   Format
+	header   string
+	declared uint32
+	count    uint32
+	start    int64
+	closed   bool
+}
+
+// NewEncoder writes the header to w and returns an Encoder for the given format.
+// For binary output the triangle count is not known up front, so Close
+// back-patches it, which requires w to be an io.WriteSeeker.
+// Use NewBinaryEncoder when the count is known and w cannot seek.
+func NewEncoder(w io.Writer, format Format, header string) (*Encoder, error) {
+	switch format {
+	case FormatASCII:
+		e := &Encoder{w: w, bw: bufio.NewWriter(w), format: format, header: header}
+		if _, err := e.bw.WriteString("solid " + header + "\n"); err != nil {
+			return nil, fmt.Errorf("did not write header: %w", err)
+		}
+		return e, nil
+	case FormatBinary:
+		return NewBinaryEncoder(w, header, 0)
+	default:
+		return nil, fmt.Errorf("cannot encode %s format", format)
+	}
+}
+
+// NewBinaryEncoder writes the header and the declared triangle count to w and
+// returns a binary Encoder.
+// If a different number of Triangles is written, Close back-patches the count
+// when w is an io.WriteSeeker and returns an error otherwise.
+func NewBinaryEncoder(w io.Writer, header string, count uint32) (*Encoder, error) {
+	e := &Encoder{w: w, bw: bufio.NewWriter(w), format: FormatBinary, header: header, declared: count}
+
+	// Remember where the output starts so the count can be found again
+	if ws, ok := w.(io.WriteSeeker); ok {
+		start, err := ws.Seek(0, io.SeekCurrent)
+		if err != nil {
+			return nil, fmt.Errorf("could not get output position: %w", err)
+		}
+		e.start = start
+	}
+
+	if _, err := e.bw.Write(headerBinary(header)); err != nil {
+		return nil, fmt.Errorf("did not write header: %w", err)
+	}
+	if _, err := e.bw.Write(triCountBinary(count)); err != nil {
+		return nil, fmt.Errorf("did not write triangle count: %w", err)
+	}
+
+	return e, nil
+}
+
+// WriteTriangle writes a single Triangle
+func (e *Encoder) WriteTriangle(t Triangle) error {
+	if e.closed {
+		return fmt.Errorf("encoder is closed")
+	}
+	if e.format == FormatBinary && e.count == 1<<32-1 {
+		return fmt.Errorf("binary format cannot hold more than %d triangles", uint32(1<<32-1))
+	}
+
+	if e.RecomputeNormals {
+		t.Normal = t.ComputeNormal()
+	}
+
+	var err error
+	if e.format == FormatASCII {
+		_, err = e.bw.WriteString(triangleASCII(t))
+	} else {
+		_, err = e.bw.Write(triangleBinary(t))
+	}
+	if err != nil {
+		return fmt.Errorf("did not write triangle: %w", err)
+	}
+	e.count++
+
+	return nil
+}
+
+// WriteTriangles writes each Triangle in order
+func (e *Encoder) WriteTriangles(tris []Triangle) error {
+	for _, t := range tris {
+		if err := e.WriteTriangle(t); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Count is the number of Triangles written so far
+func (e *Encoder) Count() uint32 {
+	return e.count
+}
+
+// Close finishes the output.
+// For ASCII it writes the "endsolid" footer, and for binary it makes sure the
+// triangle count in the header is correct.
+// It does not close the underlying io.Writer.
+func (e *Encoder) Close() error {
+	if e.closed {
+		return nil
+	}
+	e.closed = true
+
+	if e.format == FormatASCII {
+		if _, err := e.bw.WriteString("endsolid " + e.header + "\n"); err != nil {
+			return fmt.Errorf("did not write footer: %w", err)
+		}
+		if err := e.bw.Flush(); err != nil {
+			return fmt.Errorf("did not write triangles: %w", err)
+		}
+		return nil
+	}
+
+	if err := e.bw.Flush(); err != nil {
+		return fmt.Errorf("did not write triangles: %w", err)
+	}
+	if e.count == e.declared {
+		return nil
+	}
+
+	return e.patchCount()
+}
+func (e *Encoder) patchCount() error {
+	ws, ok := e.w.(io.WriteSeeker)
+	if !ok {
+		return fmt.Errorf("%w: wrote %d triangles but declared %d, and output cannot seek to fix the count", ErrCountMismatch, e.count, e.declared)
+	}
+
+	end, err := ws.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return fmt.Errorf("could not get output position: %w", err)
+	}
+	if _, err := ws.Seek(e.start+80, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek to triangle count: %w", err)
+	}
+	if _, err := ws.Write(triCountBinary(e.count)); err != nil {
+		return fmt.Errorf("did not write triangle count: %w", err)
+	}
+	if _, err := ws.Seek(end, io.SeekStart); err != nil {
+		return fmt.Errorf("could not seek to end of output: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,117 @@
+package stl
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEncoder_ASCII(t *testing.T) {
+	solid := testSolid()
+	want := &bytes.Buffer{}
+	if err := solid.ToASCII(want); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	got := &bytes.Buffer{}
+	e, err := NewEncoder(got, FormatASCII, solid.Header)
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	if err := e.WriteTriangles(solid.Triangles); err != nil {
+		t.Fatalf("could not write triangles: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("could not close encoder: %v", err)
+	}
+
+	if !bytes.Equal(got.Bytes(), want.Bytes()) {
+		t.Errorf("got \n%s\nwant \n%s", got, want)
+	}
+}
+func TestEncoder_BinarySeeker(t *testing.T) {
+	solid := testSolid()
+	want := &bytes.Buffer{}
+	if err := solid.ToBinary(want); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	file, err := os.Create(filepath.Join(t.TempDir(), "out.stl"))
+	if err != nil {
+		t.Fatalf("could not create file: %v", err)
+	}
+	defer file.Close()
+
+	// Start part way into the file to check the count is patched relative to the start of output
+	if _, err := file.WriteString("prefix"); err != nil {
+		t.Fatalf("could not write prefix: %v", err)
+	}
+
+	e, err := NewEncoder(file, FormatBinary, solid.Header)
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	for _, tri := range solid.Triangles {
+		if err := e.WriteTriangle(tri); err != nil {
+			t.Fatalf("could not write triangle: %v", err)
+		}
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("could not close encoder: %v", err)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		t.Fatalf("could not seek: %v", err)
+	}
+	got, err := io.ReadAll(file)
+	if err != nil {
+		t.Fatalf("could not read file: %v", err)
+	}
+	if !bytes.Equal(got, append([]byte("prefix"), want.Bytes()...)) {
+		t.Errorf("got %x; want prefix + %x", got, want.Bytes())
+	}
+}
+func TestEncoder_BinaryDeclaredCount(t *testing.T) {
+	solid := testSolid()
+	for _, tst := range []struct {
+		name     string
+		declared uint32
+		want     error
+	}{
+		{
+			name:     "matching count",
+			declared: 3,
+			want:     nil,
+		},
+		{
+			name:     "mismatched count without seeking",
+			declared: 2,
+			want:     ErrCountMismatch,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			buf := &bytes.Buffer{}
+			e, err := NewBinaryEncoder(buf, solid.Header, tst.declared)
+			if err != nil {
+				t.Fatalf("could not create encoder: %v", err)
+			}
+			if err := e.WriteTriangles(solid.Triangles); err != nil {
+				t.Fatalf("could not write triangles: %v", err)
+			}
+			if err := e.Close(); !errors.Is(err, tst.want) {
+				t.Errorf("got %v; want %v", err, tst.want)
+			}
+			if e.Count() != 3 {
+				t.Errorf("got count %d; want 3", e.Count())
+			}
+			if err := e.WriteTriangle(Triangle{}); err == nil {
+				t.Errorf("got no error writing after close; want an error")
+			}
+		})
+	}
+}
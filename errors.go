@@ -0,0 +1,66 @@
+package stl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	// ErrEmpty is returned when the input has no content
+	ErrEmpty = errors.New("input has no content")
+	// ErrTruncated is returned when the input ends part way through the header or a facet
+	ErrTruncated = errors.New("input is truncated")
+	// ErrSyntax is returned when ASCII input does not follow the STL grammar
+	ErrSyntax = errors.New("invalid input")
+	// ErrCountMismatch is returned when a triangle count does not match the triangles present
+	ErrCountMismatch = errors.New("triangle count does not match data")
+	// ErrTooLarge is returned when the input is larger than ReadOptions.MaxBytes
+	ErrTooLarge = errors.New("input too large")
+	// ErrTooManyTriangles is returned when the input has more than ReadOptions.MaxTriangles
+	ErrTooManyTriangles = errors.New("too many triangles")
+	// ErrNoTriangles is returned when writing a Solid without Triangles to a format that needs them
+	ErrNoTriangles = errors.New("solid has no triangles")
+)
+
+// ParseError is a problem found while reading, and where in the input it was found.
+// Use errors.Is on it to check for the sentinel errors above.
+type ParseError struct {
+	// Format of the input being read
+	Format Format
+	// Line is the 1-based line number for ASCII input, or 0 when not known
+	Line int
+	// Facet is the 0-based index of the facet, or -1 when not in a facet
+	Facet int
+	// Offset is the number of bytes from the start of the input
+	Offset int64
+	// Err is the underlying problem
+	Err error
+}
+
+func (e *ParseError) Error() string {
+	loc := []string{e.Format.String()}
+	if e.Line > 0 {
+		loc = append(loc, fmt.Sprintf("line %d", e.Line))
+	}
+	if e.Facet >= 0 {
+		loc = append(loc, fmt.Sprintf("facet %d", e.Facet))
+	}
+	loc = append(loc, fmt.Sprintf("byte %d", e.Offset))
+
+	return strings.Join(loc, ", ") + ": " + e.Err.Error()
+}
+
+// Unwrap returns the underlying problem
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// rebase moves a ParseError found relative to a chunk to its position in the whole input
+func (e *ParseError) rebase(line int, facet int, offset int64) *ParseError {
+	e.Line += line
+	e.Facet += facet
+	e.Offset += offset
+
+	return e
+}
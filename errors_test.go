@@ -0,0 +1,124 @@
+package stl
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestParseError_Error(t *testing.T) {
+	for _, tst := range []struct {
+		in   ParseError
+		want string
+	}{
+		{
+			in:   ParseError{Format: FormatASCII, Line: 9, Facet: 1, Offset: 176, Err: ErrSyntax},
+			want: "ASCII, line 9, facet 1, byte 176: invalid input",
+		},
+		{
+			in:   ParseError{Format: FormatBinary, Facet: 3, Offset: 234, Err: ErrTruncated},
+			want: "binary, facet 3, byte 234: input is truncated",
+		},
+		{
+			in:   ParseError{Format: FormatBinary, Facet: -1, Offset: 80, Err: ErrTruncated},
+			want: "binary, byte 80: input is truncated",
+		},
+	} {
+		tst := tst
+		t.Run(tst.want, func(t *testing.T) {
+			t.Parallel()
+			if got := tst.in.Error(); got != tst.want {
+				t.Errorf("got %q; want %q", got, tst.want)
+			}
+		})
+	}
+}
+func TestParseError_Location(t *testing.T) {
+	solid := testSolid()
+	ascii, bin := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := solid.ToASCII(ascii); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	if err := solid.ToBinary(bin); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	// Break the second vertex of the third facet
+	badVertex := strings.Replace(ascii.String(), "vertex 2 1 0", "vertex 2 x 0", 1)
+	// Drop the end of the last facet
+	truncatedASCII := ascii.String()[:strings.LastIndex(ascii.String(), "  endloop")]
+
+	for _, tst := range []struct {
+		name string
+		in   []byte
+		want ParseError
+		is   error
+	}{
+		{
+			name: "ASCII bad vertex",
+			in:   []byte(badVertex),
+			want: ParseError{Format: FormatASCII, Line: 19, Facet: 2, Offset: int64(strings.Index(badVertex, "   vertex 2 x 0"))},
+			is:   ErrSyntax,
+		},
+		{
+			name: "ASCII truncated",
+			in:   []byte(truncatedASCII),
+			want: ParseError{Format: FormatASCII, Line: 16, Facet: 2, Offset: int64(strings.LastIndex(truncatedASCII, " facet"))},
+			is:   ErrTruncated,
+		},
+		{
+			name: "ASCII without a newline",
+			in:   []byte("solid test"),
+			want: ParseError{Format: FormatASCII, Line: 1, Facet: -1, Offset: 0},
+			is:   ErrTruncated,
+		},
+		{
+			name: "binary truncated triangle",
+			in:   bin.Bytes()[:bin.Len()-10],
+			want: ParseError{Format: FormatBinary, Facet: 2, Offset: 184},
+			is:   ErrTruncated,
+		},
+		{
+			name: "binary truncated count",
+			in:   bin.Bytes()[:82],
+			want: ParseError{Format: FormatBinary, Facet: -1, Offset: 80},
+			is:   ErrTruncated,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			check := func(err error) {
+				var got *ParseError
+				if !errors.As(err, &got) {
+					t.Fatalf("got %v; want a *ParseError", err)
+				}
+				if !errors.Is(err, tst.is) {
+					t.Errorf("got %v; want %v", err, tst.is)
+				}
+				if got.Format != tst.want.Format || got.Line != tst.want.Line || got.Facet != tst.want.Facet || got.Offset != tst.want.Offset {
+					t.Errorf("got %s, line %d, facet %d, byte %d; want %s, line %d, facet %d, byte %d",
+						got.Format, got.Line, got.Facet, got.Offset, tst.want.Format, tst.want.Line, tst.want.Facet, tst.want.Offset)
+				}
+			}
+
+			// Read all at once
+			_, err := From(bytes.NewReader(tst.in))
+			check(err)
+
+			// Read as a stream
+			d, err := NewDecoder(bytes.NewReader(tst.in))
+			if err == nil {
+				for _, err = range d.All() {
+				}
+			}
+			check(err)
+		})
+	}
+}
+func TestFrom_Empty(t *testing.T) {
+	if _, err := From(bytes.NewReader(nil)); !errors.Is(err, ErrEmpty) {
+		t.Errorf("got %v; want %v", err, ErrEmpty)
+	}
+}
@@ -1,3 +1,3 @@
 module gitlab.com/russoj88/stl
 
-go 1.12
+go 1.23
@@ -0,0 +1,163 @@
+package stl
+
+import "math"
+
+// Matrix is a 4x4 affine transform in row-major order, applied to column vectors.
+// Combine transforms with Mul.
+type Matrix [4][4]float64
+
+// Identity is the transform that changes nothing
+func Identity() Matrix {
+	return Matrix{
+		{1, 0, 0, 0},
+		{0, 1, 0, 0},
+		{0, 0, 1, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// Translate moves by x, y and z
+func Translate(x, y, z float64) Matrix {
+	m := Identity()
+	m[0][3], m[1][3], m[2][3] = x, y, z
+	return m
+}
+
+// Scale scales each axis about the origin.  A negative factor mirrors that axis.
+func Scale(x, y, z float64) Matrix {
+	m := Identity()
+	m[0][0], m[1][1], m[2][2] = x, y, z
+	return m
+}
+
+// ScaleUniform scales every axis about the origin by f
+func ScaleUniform(f float64) Matrix {
+	return Scale(f, f, f)
+}
+
+// RotateAxis rotates by angle radians about axis through the origin, counter-clockwise
+// when looking back along axis (the right-hand rule)
+func RotateAxis(axis Vec3, angle float64) Matrix {
+	a := axis.Normalize()
+	s, c := math.Sincos(angle)
+	t := 1 - c
+
+	return Matrix{
+		{t*a.X*a.X + c, t*a.X*a.Y - s*a.Z, t*a.X*a.Z + s*a.Y, 0},
+		{t*a.X*a.Y + s*a.Z, t*a.Y*a.Y + c, t*a.Y*a.Z - s*a.X, 0},
+		{t*a.X*a.Z - s*a.Y, t*a.Y*a.Z + s*a.X, t*a.Z*a.Z + c, 0},
+		{0, 0, 0, 1},
+	}
+}
+
+// RotateEuler rotates by x radians about the X axis, then y about the Y axis, then z about the Z axis
+func RotateEuler(x, y, z float64) Matrix {
+	return RotateAxis(Vec3{Z: 1}, z).Mul(RotateAxis(Vec3{Y: 1}, y)).Mul(RotateAxis(Vec3{X: 1}, x))
+}
+
+// Mirror reflects across the plane through point with the given normal
+func Mirror(point, normal Vec3) Matrix {
+	n := normal.Normalize()
+	d := n.Dot(point)
+
+	m := Identity()
+	nv := [3]float64{n.X, n.Y, n.Z}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			m[i][j] -= 2 * nv[i] * nv[j]
+		}
+		m[i][3] = 2 * d * nv[i]
+	}
+
+	return m
+}
+
+// Mul is m * n, the transform that applies n and then m
+func (m Matrix) Mul(n Matrix) Matrix {
+	var r Matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			for k := 0; k < 4; k++ {
+				r[i][j] += m[i][k] * n[k][j]
+			}
+		}
+	}
+
+	return r
+}
+
+// Apply transforms the point v
+func (m Matrix) Apply(v Vec3) Vec3 {
+	return Vec3{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z + m[0][3],
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z + m[1][3],
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z + m[2][3],
+	}
+}
+
+// ApplyVector transforms the direction v, ignoring translation
+func (m Matrix) ApplyVector(v Vec3) Vec3 {
+	return Vec3{
+		X: m[0][0]*v.X + m[0][1]*v.Y + m[0][2]*v.Z,
+		Y: m[1][0]*v.X + m[1][1]*v.Y + m[1][2]*v.Z,
+		Z: m[2][0]*v.X + m[2][1]*v.Y + m[2][2]*v.Z,
+	}
+}
+
+// Transpose swaps the rows and columns of m
+func (m Matrix) Transpose() Matrix {
+	var r Matrix
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			r[i][j] = m[j][i]
+		}
+	}
+
+	return r
+}
+
+// Determinant is the determinant of the linear part of m.
+// It is negative when m mirrors, and zero when m flattens.
+func (m Matrix) Determinant() float64 {
+	return m[0][0]*(m[1][1]*m[2][2]-m[1][2]*m[2][1]) -
+		m[0][1]*(m[1][0]*m[2][2]-m[1][2]*m[2][0]) +
+		m[0][2]*(m[1][0]*m[2][1]-m[1][1]*m[2][0])
+}
+
+// Inverse is the transform that undoes m.  ok is false when m flattens and cannot be undone.
+func (m Matrix) Inverse() (inv Matrix, ok bool) {
+	det := m.Determinant()
+	if det == 0 {
+		return Matrix{}, false
+	}
+
+	// Inverse of the linear part is its adjugate over the determinant
+	cof := m.cofactor()
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			inv[i][j] = cof[j][i] / det
+		}
+	}
+
+	// Undo the translation
+	t := inv.ApplyVector(Vec3{X: m[0][3], Y: m[1][3], Z: m[2][3]})
+	inv[0][3], inv[1][3], inv[2][3] = -t.X, -t.Y, -t.Z
+	inv[3][3] = 1
+
+	return inv, true
+}
+
+// cofactor is the cofactor matrix of the linear part of m
+func (m Matrix) cofactor() Matrix {
+	var c Matrix
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			r1, r2 := (i+1)%3, (i+2)%3
+			c1, c2 := (j+1)%3, (j+2)%3
+			c[i][j] = m[r1][c1]*m[r2][c2] - m[r1][c2]*m[r2][c1]
+		}
+	}
+	c[3][3] = 1
+
+	return c
+}
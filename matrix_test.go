@@ -0,0 +1,95 @@
+package stl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestMatrix_Apply(t *testing.T) {
+	for _, tst := range []struct {
+		name string
+		m    Matrix
+		in   Vec3
+		want Vec3
+	}{
+		{
+			name: "translate",
+			m:    Translate(1, 2, 3),
+			in:   Vec3{X: 1, Y: 1, Z: 1},
+			want: Vec3{X: 2, Y: 3, Z: 4},
+		},
+		{
+			name: "scale",
+			m:    Scale(2, 3, -1),
+			in:   Vec3{X: 1, Y: 1, Z: 1},
+			want: Vec3{X: 2, Y: 3, Z: -1},
+		},
+		{
+			name: "uniform scale",
+			m:    ScaleUniform(0.5),
+			in:   Vec3{X: 2, Y: 4, Z: 6},
+			want: Vec3{X: 1, Y: 2, Z: 3},
+		},
+		{
+			name: "rotate about Z",
+			m:    RotateAxis(Vec3{Z: 2}, math.Pi/2),
+			in:   Vec3{X: 1},
+			want: Vec3{Y: 1},
+		},
+		{
+			name: "rotate about a diagonal",
+			m:    RotateAxis(Vec3{X: 1, Y: 1, Z: 1}, 2*math.Pi/3),
+			in:   Vec3{X: 1},
+			want: Vec3{Y: 1},
+		},
+		{
+			name: "Euler applies X first",
+			m:    RotateEuler(math.Pi/2, 0, math.Pi/2),
+			in:   Vec3{Y: 1},
+			want: Vec3{Z: 1},
+		},
+		{
+			name: "mirror",
+			m:    Mirror(Vec3{X: 1}, Vec3{X: -2}),
+			in:   Vec3{X: 3, Y: 1},
+			want: Vec3{X: -1, Y: 1},
+		},
+		{
+			name: "applies the right side first",
+			m:    Translate(1, 0, 0).Mul(ScaleUniform(2)),
+			in:   Vec3{X: 1},
+			want: Vec3{X: 3},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tst.m.Apply(tst.in); !nearVec(got, tst.want) {
+				t.Errorf("got %+v; want %+v", got, tst.want)
+			}
+		})
+	}
+}
+func TestMatrix_Inverse(t *testing.T) {
+	m := Translate(1, -2, 3).Mul(RotateEuler(0.3, 0.2, 0.1)).Mul(Scale(2, 3, -4))
+	if got := m.Determinant(); !near(got, -24) {
+		t.Errorf("got determinant %g; want -24", got)
+	}
+
+	inv, ok := m.Inverse()
+	if !ok {
+		t.Fatalf("got no inverse")
+	}
+	got, want := inv.Mul(m), Identity()
+	for i := 0; i < 4; i++ {
+		for j := 0; j < 4; j++ {
+			if math.Abs(got[i][j]-want[i][j]) > 1e-12 {
+				t.Fatalf("got %v for inverse times matrix; want identity", got)
+			}
+		}
+	}
+
+	if _, ok := Scale(1, 1, 0).Inverse(); ok {
+		t.Errorf("got an inverse for a flattening matrix")
+	}
+}
@@ -0,0 +1,234 @@
+package stl
+
+import (
+	"math"
+	"sort"
+)
+
+// Box is an axis-aligned bounding box
+type Box struct {
+	Min Vec3
+	Max Vec3
+}
+
+// Size is the extent of the Box along each axis
+func (b Box) Size() Vec3 {
+	return b.Max.Sub(b.Min)
+}
+
+// Center is the middle of the Box
+func (b Box) Center() Vec3 {
+	return b.Min.Add(b.Max).Scale(0.5)
+}
+
+// MassProperties are the physical properties of a Solid of uniform unit density
+type MassProperties struct {
+	// Volume is the signed enclosed volume, which is also the mass
+	Volume float64
+	// SurfaceArea is the total area of the Triangles
+	SurfaceArea float64
+	// Centroid is the center of mass
+	Centroid Vec3
+	// Inertia is the inertia tensor about the Centroid
+	Inertia [3][3]float64
+	// PrincipalMoments are the eigenvalues of Inertia, smallest first
+	PrincipalMoments [3]float64
+	// PrincipalAxes are the unit eigenvectors of Inertia, in the order of PrincipalMoments
+	PrincipalAxes [3]Vec3
+}
+
+// BoundingBox is the smallest axis-aligned Box holding every vertex.
+// A Solid without Triangles has the zero Box.
+func (s *Solid) BoundingBox() Box {
+	if len(s.Triangles) == 0 {
+		return Box{}
+	}
+
+	first := s.Triangles[0].Vertices[0].Vec3()
+	b := Box{Min: first, Max: first}
+	for _, t := range s.Triangles {
+		for _, c := range t.Vertices {
+			v := c.Vec3()
+			b.Min = Vec3{X: math.Min(b.Min.X, v.X), Y: math.Min(b.Min.Y, v.Y), Z: math.Min(b.Min.Z, v.Z)}
+			b.Max = Vec3{X: math.Max(b.Max.X, v.X), Y: math.Max(b.Max.Y, v.Y), Z: math.Max(b.Max.Z, v.Z)}
+		}
+	}
+
+	return b
+}
+
+// SurfaceArea is the total area of the Triangles
+func (s *Solid) SurfaceArea() float64 {
+	area := 0.0
+	for _, t := range s.Triangles {
+		area += t.Area()
+	}
+
+	return area
+}
+
+// Volume is the signed volume enclosed by the Triangles, found with the divergence theorem.
+// It is positive when vertices wind counter-clockwise seen from outside, and only
+// meaningful for a closed mesh.  A void wound inward, as Repair leaves it, has a
+// negative volume that is taken from the shell around it.
+func (s *Solid) Volume() float64 {
+	vol := 0.0
+	for _, t := range s.Triangles {
+		a, b, c := t.Vertices[0].Vec3(), t.Vertices[1].Vec3(), t.Vertices[2].Vec3()
+		vol += a.Dot(b.Cross(c))
+	}
+
+	return vol / 6
+}
+
+// SurfaceCentroid is the area-weighted center of the Triangles
+func (s *Solid) SurfaceCentroid() Vec3 {
+	var sum Vec3
+	area := 0.0
+	for _, t := range s.Triangles {
+		a := t.Area()
+		sum = sum.Add(t.center().Scale(a))
+		area += a
+	}
+	if area == 0 {
+		return Vec3{}
+	}
+
+	return sum.Scale(1 / area)
+}
+
+// Centroid is the volume-weighted center of the enclosed volume.
+// For an open or flat mesh with no volume it is the SurfaceCentroid.
+func (s *Solid) Centroid() Vec3 {
+	var sum Vec3
+	vol := 0.0
+	for _, t := range s.Triangles {
+		a, b, c := t.Vertices[0].Vec3(), t.Vertices[1].Vec3(), t.Vertices[2].Vec3()
+
+		// Each Triangle makes a tetrahedron with the origin
+		v := a.Dot(b.Cross(c)) / 6
+		sum = sum.Add(a.Add(b).Add(c).Scale(v / 4))
+		vol += v
+	}
+	if vol == 0 {
+		return s.SurfaceCentroid()
+	}
+
+	return sum.Scale(1 / vol)
+}
+
+// MassProperties is the volume, centroid, inertia tensor and principal axes of the
+// enclosed volume at unit density.  Scale Volume and Inertia by the density for other materials.
+func (s *Solid) MassProperties() MassProperties {
+	mp := MassProperties{
+		SurfaceArea: s.SurfaceArea(),
+		Centroid:    s.Centroid(),
+	}
+
+	// Second moment of volume about the origin, summed over the tetrahedron each
+	// Triangle makes with the origin.  For a tetrahedron with edges a, b and c from
+	// the origin it is det/120 * (sum of v*v' over vertices + (a+b+c)(a+b+c)')
+	var cov [3][3]float64
+	for _, t := range s.Triangles {
+		a, b, c := t.Vertices[0].Vec3(), t.Vertices[1].Vec3(), t.Vertices[2].Vec3()
+		det := a.Dot(b.Cross(c))
+		mp.Volume += det / 6
+
+		sum := vecArray(a.Add(b).Add(c))
+		for i := 0; i < 3; i++ {
+			for j := 0; j < 3; j++ {
+				outer := sum[i] * sum[j]
+				for _, v := range [3][3]float64{vecArray(a), vecArray(b), vecArray(c)} {
+					outer += v[i] * v[j]
+				}
+				cov[i][j] += det / 120 * outer
+			}
+		}
+	}
+
+	// Move to the centroid, then convert to an inertia tensor
+	cent := vecArray(mp.Centroid)
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			cov[i][j] -= mp.Volume * cent[i] * cent[j]
+		}
+	}
+	trace := cov[0][0] + cov[1][1] + cov[2][2]
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			mp.Inertia[i][j] = -cov[i][j]
+		}
+		mp.Inertia[i][i] += trace
+	}
+
+	mp.PrincipalMoments, mp.PrincipalAxes = eigenSymmetric(mp.Inertia)
+
+	return mp
+}
+
+// Area is the area of the Triangle
+func (t Triangle) Area() float64 {
+	a, b, c := t.Vertices[0].Vec3(), t.Vertices[1].Vec3(), t.Vertices[2].Vec3()
+	return b.Sub(a).Cross(c.Sub(a)).Len() / 2
+}
+
+// center is the mean of the vertices
+func (t Triangle) center() Vec3 {
+	return t.Vertices[0].Vec3().Add(t.Vertices[1].Vec3()).Add(t.Vertices[2].Vec3()).Scale(1.0 / 3)
+}
+func vecArray(v Vec3) [3]float64 {
+	return [3]float64{v.X, v.Y, v.Z}
+}
+
+// eigenSymmetric finds the eigenvalues, smallest first, and unit eigenvectors
+// of a symmetric matrix with the Jacobi method
+func eigenSymmetric(m [3][3]float64) ([3]float64, [3]Vec3) {
+	vec := [3][3]float64{{1, 0, 0}, {0, 1, 0}, {0, 0, 1}}
+
+	for sweep := 0; sweep < 50; sweep++ {
+		off := m[0][1]*m[0][1] + m[0][2]*m[0][2] + m[1][2]*m[1][2]
+		diag := m[0][0]*m[0][0] + m[1][1]*m[1][1] + m[2][2]*m[2][2]
+		if off <= 1e-30*diag || off == 0 {
+			break
+		}
+
+		// Zero each off-diagonal element in turn with a rotation
+		for p := 0; p < 2; p++ {
+			for q := p + 1; q < 3; q++ {
+				if m[p][q] == 0 {
+					continue
+				}
+				theta := (m[q][q] - m[p][p]) / (2 * m[p][q])
+				t := math.Copysign(1, theta) / (math.Abs(theta) + math.Sqrt(theta*theta+1))
+				c := 1 / math.Sqrt(t*t+1)
+				s := t * c
+
+				for k := 0; k < 3; k++ {
+					mkp, mkq := m[k][p], m[k][q]
+					m[k][p], m[k][q] = c*mkp-s*mkq, s*mkp+c*mkq
+				}
+				for k := 0; k < 3; k++ {
+					mpk, mqk := m[p][k], m[q][k]
+					m[p][k], m[q][k] = c*mpk-s*mqk, s*mpk+c*mqk
+				}
+				for k := 0; k < 3; k++ {
+					vkp, vkq := vec[k][p], vec[k][q]
+					vec[k][p], vec[k][q] = c*vkp-s*vkq, s*vkp+c*vkq
+				}
+			}
+		}
+	}
+
+	// Sort by eigenvalue.  Eigenvectors are the columns of vec.
+	order := []int{0, 1, 2}
+	sort.Slice(order, func(i, j int) bool { return m[order[i]][order[i]] < m[order[j]][order[j]] })
+
+	var vals [3]float64
+	var axes [3]Vec3
+	for i, k := range order {
+		vals[i] = m[k][k]
+		axes[i] = Vec3{X: vec[0][k], Y: vec[1][k], Z: vec[2][k]}
+	}
+
+	return vals, axes
+}
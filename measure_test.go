@@ -0,0 +1,150 @@
+package stl
+
+import (
+	"math"
+	"testing"
+)
+
+// testBox is a closed box from the origin to x, y, z with outward facing normals
+func testBox(x, y, z float32) Solid {
+	tri := func(n UnitVector, a, b, c Coordinate) Triangle {
+		return Triangle{Normal: n, Vertices: [3]Coordinate{a, b, c}}
+	}
+	p := func(px, py, pz float32) Coordinate { return Coordinate{X: px, Y: py, Z: pz} }
+
+	tris := []Triangle{
+		tri(UnitVector{Nk: -1}, p(0, 0, 0), p(0, y, 0), p(x, y, 0)),
+		tri(UnitVector{Nk: -1}, p(0, 0, 0), p(x, y, 0), p(x, 0, 0)),
+		tri(UnitVector{Nk: 1}, p(0, 0, z), p(x, 0, z), p(x, y, z)),
+		tri(UnitVector{Nk: 1}, p(0, 0, z), p(x, y, z), p(0, y, z)),
+		tri(UnitVector{Nj: -1}, p(0, 0, 0), p(x, 0, 0), p(x, 0, z)),
+		tri(UnitVector{Nj: -1}, p(0, 0, 0), p(x, 0, z), p(0, 0, z)),
+		tri(UnitVector{Nj: 1}, p(0, y, 0), p(0, y, z), p(x, y, z)),
+		tri(UnitVector{Nj: 1}, p(0, y, 0), p(x, y, z), p(x, y, 0)),
+		tri(UnitVector{Ni: -1}, p(0, 0, 0), p(0, 0, z), p(0, y, z)),
+		tri(UnitVector{Ni: -1}, p(0, 0, 0), p(0, y, z), p(0, y, 0)),
+		tri(UnitVector{Ni: 1}, p(x, 0, 0), p(x, y, 0), p(x, y, z)),
+		tri(UnitVector{Ni: 1}, p(x, 0, 0), p(x, y, z), p(x, 0, z)),
+	}
+
+	return Solid{Header: "box", TriangleCount: uint32(len(tris)), Triangles: tris}
+}
+
+// near reports whether a and b are equal to within 1e-9
+func near(a, b float64) bool {
+	return math.Abs(a-b) <= 1e-9*math.Max(1, math.Max(math.Abs(a), math.Abs(b)))
+}
+func nearVec(a, b Vec3) bool {
+	return near(a.X, b.X) && near(a.Y, b.Y) && near(a.Z, b.Z)
+}
+func TestSolid_Measurements(t *testing.T) {
+	for _, tst := range []struct {
+		name         string
+		in           Solid
+		wantBox      Box
+		wantArea     float64
+		wantVolume   float64
+		wantCentroid Vec3
+	}{
+		{
+			name:         "unit cube",
+			in:           testBox(1, 1, 1),
+			wantBox:      Box{Max: Vec3{X: 1, Y: 1, Z: 1}},
+			wantArea:     6,
+			wantVolume:   1,
+			wantCentroid: Vec3{X: 0.5, Y: 0.5, Z: 0.5},
+		},
+		{
+			name:         "box",
+			in:           testBox(1, 2, 3),
+			wantBox:      Box{Max: Vec3{X: 1, Y: 2, Z: 3}},
+			wantArea:     22,
+			wantVolume:   6,
+			wantCentroid: Vec3{X: 0.5, Y: 1, Z: 1.5},
+		},
+		{
+			name: "empty",
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tst.in.BoundingBox(); !nearVec(got.Min, tst.wantBox.Min) || !nearVec(got.Max, tst.wantBox.Max) {
+				t.Errorf("got box %+v; want %+v", got, tst.wantBox)
+			}
+			if got := tst.in.SurfaceArea(); !near(got, tst.wantArea) {
+				t.Errorf("got area %g; want %g", got, tst.wantArea)
+			}
+			if got := tst.in.Volume(); !near(got, tst.wantVolume) {
+				t.Errorf("got volume %g; want %g", got, tst.wantVolume)
+			}
+			if got := tst.in.Centroid(); !nearVec(got, tst.wantCentroid) {
+				t.Errorf("got centroid %+v; want %+v", got, tst.wantCentroid)
+			}
+			if got := tst.in.SurfaceCentroid(); !nearVec(got, tst.wantCentroid) {
+				t.Errorf("got surface centroid %+v; want %+v", got, tst.wantCentroid)
+			}
+		})
+	}
+}
+func TestSolid_VolumeInsideOut(t *testing.T) {
+	s := testBox(1, 2, 3)
+	for i := range s.Triangles {
+		v := &s.Triangles[i].Vertices
+		v[1], v[2] = v[2], v[1]
+	}
+
+	if got := s.Volume(); !near(got, -6) {
+		t.Errorf("got %g; want -6", got)
+	}
+}
+func TestSolid_MassProperties(t *testing.T) {
+	s := testBox(1, 2, 3)
+	got := s.MassProperties()
+
+	if !near(got.Volume, 6) || !near(got.SurfaceArea, 22) || !nearVec(got.Centroid, Vec3{X: 0.5, Y: 1, Z: 1.5}) {
+		t.Errorf("got volume %g, area %g, centroid %+v; want 6, 22, {0.5 1 1.5}", got.Volume, got.SurfaceArea, got.Centroid)
+	}
+
+	// A box of mass m has Ixx = m(y² + z²)/12
+	want := [3][3]float64{{6.5, 0, 0}, {0, 5, 0}, {0, 0, 2.5}}
+	for i := 0; i < 3; i++ {
+		for j := 0; j < 3; j++ {
+			if !near(got.Inertia[i][j], want[i][j]) {
+				t.Errorf("got inertia %v; want %v", got.Inertia, want)
+			}
+		}
+	}
+
+	// Smallest moment is about the longest side
+	wantMoments := [3]float64{2.5, 5, 6.5}
+	wantAxes := [3]Vec3{{Z: 1}, {Y: 1}, {X: 1}}
+	for i := 0; i < 3; i++ {
+		if !near(got.PrincipalMoments[i], wantMoments[i]) {
+			t.Errorf("got moment %g; want %g", got.PrincipalMoments[i], wantMoments[i])
+		}
+		if !near(math.Abs(got.PrincipalAxes[i].Dot(wantAxes[i])), 1) {
+			t.Errorf("got axis %+v; want %+v", got.PrincipalAxes[i], wantAxes[i])
+		}
+	}
+}
+func Test_eigenSymmetric(t *testing.T) {
+	m := [3][3]float64{{2, 1, 0}, {1, 2, 0}, {0, 0, 5}}
+	vals, axes := eigenSymmetric(m)
+
+	want := [3]float64{1, 3, 5}
+	for i := range vals {
+		if !near(vals[i], want[i]) {
+			t.Errorf("got eigenvalues %v; want %v", vals, want)
+		}
+
+		// m v = λ v
+		v := vecArray(axes[i])
+		for r := 0; r < 3; r++ {
+			mv := m[r][0]*v[0] + m[r][1]*v[1] + m[r][2]*v[2]
+			if !near(mv, vals[i]*v[r]) {
+				t.Errorf("got eigenvector %+v for %g; want m v = λ v", axes[i], vals[i])
+			}
+		}
+	}
+}
@@ -0,0 +1,68 @@
+package stl
+
+// Merge combines the Triangles of every Solid into one, in order.
+// See MergeTransformed.
+func Merge(solids ...Solid) Solid {
+	return MergeTransformed(solids, nil)
+}
+
+// MergeTransformed combines the Triangles of every Solid into one, in order, applying
+// transforms[i] to the Triangles of solids[i] as in Solid.Transform.  Solids without a
+// transform are left where they are.  The solids themselves are not changed.
+// The Header comes from the first Solid.  The ColorFormat, Color and Material come
+// from the first Solid with a ColorFormat, and the colors of the other Solids are
+// converted to match.  TriangleCount is the number of Triangles.
+func MergeTransformed(solids []Solid, transforms []Matrix) Solid {
+	var merged Solid
+	if len(solids) == 0 {
+		return merged
+	}
+
+	first := solids[0]
+	for _, s := range solids {
+		if s.ColorFormat != ColorNone {
+			first = s
+			break
+		}
+	}
+	merged.Header = solids[0].Header
+	merged.ColorFormat, merged.Color, merged.Material = first.ColorFormat, first.Color, first.Material
+
+	n := 0
+	for _, s := range solids {
+		n += len(s.Triangles)
+	}
+	merged.Triangles = make([]Triangle, 0, n)
+
+	for i, s := range solids {
+		start := len(merged.Triangles)
+		merged.Triangles = append(merged.Triangles, s.Triangles...)
+		part := Solid{Triangles: merged.Triangles[start:]}
+
+		if i < len(transforms) {
+			part.Transform(transforms[i])
+		}
+
+		if merged.ColorFormat != ColorNone && (s.ColorFormat != merged.ColorFormat || !sameColor(s.Color, merged.Color)) {
+			for j := range part.Triangles {
+				if c, ok := s.FacetColor(j); ok {
+					part.Triangles[j].SetColor(merged.ColorFormat, c)
+				} else {
+					part.Triangles[j].ClearColor(merged.ColorFormat)
+				}
+			}
+		}
+	}
+	merged.TriangleCount = uint32(len(merged.Triangles))
+
+	return merged
+}
+
+// sameColor reports whether a and b are both nil or the same Color
+func sameColor(a, b *Color) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return *a == *b
+}
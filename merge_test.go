@@ -0,0 +1,85 @@
+package stl
+
+import "testing"
+
+func TestMerge(t *testing.T) {
+	a := testBox(1, 1, 1)
+	b := testBox(1, 2, 3)
+	b.Header = "other"
+
+	m := Merge(a, b)
+	if len(m.Triangles) != 24 || m.TriangleCount != 24 || m.Header != "box" {
+		t.Errorf("got %d triangles, count %d, header %q; want 24, 24, %q", len(m.Triangles), m.TriangleCount, m.Header, "box")
+	}
+	if v := m.Volume(); !near(v, 7) {
+		t.Errorf("got volume %v; want 7", v)
+	}
+
+	if m := Merge(); len(m.Triangles) != 0 || m.TriangleCount != 0 {
+		t.Errorf("got %d triangles, count %d from nothing; want 0", len(m.Triangles), m.TriangleCount)
+	}
+}
+func TestMergeTransformed(t *testing.T) {
+	a := testBox(1, 1, 1)
+	b := testBox(1, 1, 1)
+
+	m := MergeTransformed([]Solid{a, b}, []Matrix{Identity(), Translate(2, 0, 0).Mul(Mirror(Vec3{}, Vec3{Y: 1}))})
+	if got, want := m.BoundingBox(), (Box{Max: Vec3{X: 3, Y: 1, Z: 1}, Min: Vec3{Y: -1}}); got != want {
+		t.Errorf("got box %+v; want %+v", got, want)
+	}
+	if r := m.Validate(0); !r.OK() {
+		t.Errorf("got invalid merge %+v", r)
+	}
+
+	// The inputs are untouched
+	if got := b.BoundingBox(); got != (Box{Max: Vec3{X: 1, Y: 1, Z: 1}}) {
+		t.Errorf("got input moved to %+v", got)
+	}
+
+	// A Solid without a transform stays where it is
+	m = MergeTransformed([]Solid{a, b}, []Matrix{Translate(0, 0, 5)})
+	if got, want := m.BoundingBox(), (Box{Max: Vec3{X: 1, Y: 1, Z: 6}}); got != want {
+		t.Errorf("got box %+v; want %+v", got, want)
+	}
+}
+func TestMerge_Colors(t *testing.T) {
+	red := Color{R: 255, A: 255}
+	blue := Color{B: 255, A: 255}
+
+	plain := testBox(1, 1, 1)
+
+	viscam := testBox(1, 1, 1)
+	viscam.ColorFormat = ColorVisCAM
+	viscam.Triangles[0].SetColor(ColorVisCAM, red)
+
+	magics := testBox(1, 1, 1)
+	magics.ColorFormat = ColorMagics
+	magics.Color = &blue
+	for i := range magics.Triangles {
+		magics.Triangles[i].ClearColor(ColorMagics)
+	}
+
+	m := Merge(plain, viscam, magics)
+	if m.ColorFormat != ColorVisCAM || m.Color != nil {
+		t.Fatalf("got format %s, color %v; want VisCAM without a default", m.ColorFormat, m.Color)
+	}
+	for _, tst := range []struct {
+		i      int
+		want   Color
+		wantOK bool
+	}{
+		{i: 0},
+		{i: 12, want: red, wantOK: true},
+		{i: 13},
+		{i: 24, want: blue, wantOK: true},
+	} {
+		if c, ok := m.FacetColor(tst.i); ok != tst.wantOK || c != tst.want {
+			t.Errorf("got triangle %d color %v, %t; want %v, %t", tst.i, c, ok, tst.want, tst.wantOK)
+		}
+	}
+
+	// Nothing to convert to without a format
+	if m := Merge(plain, plain); m.ColorFormat != ColorNone || m.Triangles[0].AttrByteCnt != 0 {
+		t.Errorf("got format %s, attribute %d; want none, 0", m.ColorFormat, m.Triangles[0].AttrByteCnt)
+	}
+}
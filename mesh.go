@@ -0,0 +1,135 @@
+package stl
+
+import "math"
+
+// IndexedMesh is a mesh with each vertex stored once and shared by the Faces that use it.
+// It uses less memory than a Solid, and makes it possible to find which Faces are neighbors.
+type IndexedMesh struct {
+	Header   string
+	Vertices []Coordinate
+	Faces    []Face
+	// ColorFormat, Color and Material are those of the Solid, for reading the AttrByteCnt of Faces
+	ColorFormat ColorFormat
+	Color       *Color
+	Material    *Material
+}
+
+// Face is a triangle of an IndexedMesh
+type Face struct {
+	// Vertices are indexes into IndexedMesh.Vertices, in winding order
+	Vertices    [3]int
+	Normal      UnitVector
+	AttrByteCnt uint16
+}
+
+// Indexed converts the Solid to an IndexedMesh, welding vertices together as in IndexedMesh.Weld.
+// merged is the number of Triangle corners that share a vertex with another,
+// which is the number of vertices saved over storing every corner.
+func (s *Solid) Indexed(tolerance float64) (mesh IndexedMesh, merged int) {
+	mesh = IndexedMesh{
+		Header:      s.Header,
+		Vertices:    make([]Coordinate, 0, 3*len(s.Triangles)),
+		Faces:       make([]Face, len(s.Triangles)),
+		ColorFormat: s.ColorFormat,
+		Color:       s.Color,
+		Material:    s.Material,
+	}
+	for i, t := range s.Triangles {
+		n := len(mesh.Vertices)
+		mesh.Vertices = append(mesh.Vertices, t.Vertices[:]...)
+		mesh.Faces[i] = Face{Vertices: [3]int{n, n + 1, n + 2}, Normal: t.Normal, AttrByteCnt: t.AttrByteCnt}
+	}
+
+	return mesh, mesh.Weld(tolerance)
+}
+
+// Solid converts the IndexedMesh back to a Solid
+func (m *IndexedMesh) Solid() Solid {
+	tris := make([]Triangle, len(m.Faces))
+	for i, f := range m.Faces {
+		tris[i] = Triangle{
+			Normal:      f.Normal,
+			Vertices:    [3]Coordinate{m.Vertices[f.Vertices[0]], m.Vertices[f.Vertices[1]], m.Vertices[f.Vertices[2]]},
+			AttrByteCnt: f.AttrByteCnt,
+		}
+	}
+
+	return Solid{
+		Header:        m.Header,
+		TriangleCount: uint32(len(tris)),
+		Triangles:     tris,
+		ColorFormat:   m.ColorFormat,
+		Color:         m.Color,
+		Material:      m.Material,
+	}
+}
+
+// Weld merges vertices that are within tolerance of each other, and returns how many were removed.
+// A tolerance of zero only merges identical vertices.
+// Otherwise each vertex is merged into the nearest earlier vertex within tolerance,
+// found with a spatial hash.  Faces whose corners are welded together are kept,
+// though they no longer have any area.
+func (m *IndexedMesh) Weld(tolerance float64) int {
+	remap := make([]int, len(m.Vertices))
+	unique := make([]Coordinate, 0, len(m.Vertices))
+
+	if tolerance <= 0 {
+		seen := make(map[Coordinate]int, len(m.Vertices))
+		for i, v := range m.Vertices {
+			j, ok := seen[v]
+			if !ok {
+				j = len(unique)
+				seen[v] = j
+				unique = append(unique, v)
+			}
+			remap[i] = j
+		}
+	} else {
+		// Cells are as wide as the tolerance, so a match is in the same or a neighboring cell
+		grid := make(map[[3]int64][]int)
+		for i, v := range m.Vertices {
+			p := v.Vec3()
+			c := gridCell(p, tolerance)
+
+			j, best := -1, tolerance
+			for dx := int64(-1); dx <= 1; dx++ {
+				for dy := int64(-1); dy <= 1; dy++ {
+					for dz := int64(-1); dz <= 1; dz++ {
+						for _, k := range grid[[3]int64{c[0] + dx, c[1] + dy, c[2] + dz}] {
+							if d := unique[k].Vec3().Sub(p).Len(); d <= best {
+								j, best = k, d
+							}
+						}
+					}
+				}
+			}
+
+			if j < 0 {
+				j = len(unique)
+				unique = append(unique, v)
+				grid[c] = append(grid[c], j)
+			}
+			remap[i] = j
+		}
+	}
+
+	for i := range m.Faces {
+		for j := range m.Faces[i].Vertices {
+			m.Faces[i].Vertices[j] = remap[m.Faces[i].Vertices[j]]
+		}
+	}
+
+	merged := len(m.Vertices) - len(unique)
+	m.Vertices = unique
+
+	return merged
+}
+
+// gridCell is the spatial hash cell holding p, for cells of the given size
+func gridCell(p Vec3, size float64) [3]int64 {
+	return [3]int64{
+		int64(math.Floor(p.X / size)),
+		int64(math.Floor(p.Y / size)),
+		int64(math.Floor(p.Z / size)),
+	}
+}
@@ -0,0 +1,106 @@
+package stl
+
+import "testing"
+
+func TestSolid_Indexed(t *testing.T) {
+	box := testBox(1, 2, 3)
+
+	// Nudge one corner so it only welds with a tolerance
+	nudged := testBox(1, 2, 3)
+	nudged.Triangles[0].Vertices[0].X += 1e-4
+
+	// Magics colors need the Solid's format and default color to be read
+	colored := testBox(1, 2, 3)
+	colored.ColorFormat = ColorMagics
+	colored.Color = &Color{G: 255, A: 255}
+	colored.Material = &Material{}
+	colored.Triangles[2].SetColor(ColorMagics, Color{R: 255, A: 255})
+
+	for _, tst := range []struct {
+		name         string
+		in           Solid
+		tolerance    float64
+		wantVertices int
+	}{
+		{
+			name:         "exact",
+			in:           box,
+			wantVertices: 8,
+		},
+		{
+			name:         "exact with a nudged corner",
+			in:           nudged,
+			wantVertices: 9,
+		},
+		{
+			name:         "tolerance with a nudged corner",
+			in:           nudged,
+			tolerance:    1e-3,
+			wantVertices: 8,
+		},
+		{
+			name:         "colored",
+			in:           colored,
+			wantVertices: 8,
+		},
+		{
+			name:         "empty",
+			wantVertices: 0,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			mesh, merged := tst.in.Indexed(tst.tolerance)
+			if len(mesh.Vertices) != tst.wantVertices {
+				t.Errorf("got %d vertices; want %d", len(mesh.Vertices), tst.wantVertices)
+			}
+			if want := 3*len(tst.in.Triangles) - tst.wantVertices; merged != want {
+				t.Errorf("got %d merged; want %d", merged, want)
+			}
+			if len(mesh.Faces) != len(tst.in.Triangles) {
+				t.Fatalf("got %d faces; want %d", len(mesh.Faces), len(tst.in.Triangles))
+			}
+
+			// Converting back keeps every Triangle, with welded corners moved onto their match
+			back := mesh.Solid()
+			if back.TriangleCount != uint32(len(tst.in.Triangles)) {
+				t.Errorf("got count %d; want %d", back.TriangleCount, len(tst.in.Triangles))
+			}
+			if back.ColorFormat != tst.in.ColorFormat || back.Color != tst.in.Color || back.Material != tst.in.Material {
+				t.Errorf("got colors %s %v %v; want %s %v %v", back.ColorFormat, back.Color, back.Material, tst.in.ColorFormat, tst.in.Color, tst.in.Material)
+			}
+			for i := range back.Triangles {
+				if got, want := back.Triangles[i].AttrByteCnt, tst.in.Triangles[i].AttrByteCnt; got != want {
+					t.Errorf("got attribute %#x for triangle %d; want %#x", got, i, want)
+				}
+				if back.Triangles[i].Normal != tst.in.Triangles[i].Normal {
+					t.Errorf("got normal %+v for triangle %d; want %+v", back.Triangles[i].Normal, i, tst.in.Triangles[i].Normal)
+				}
+				for j := range back.Triangles[i].Vertices {
+					d := back.Triangles[i].Vertices[j].Vec3().Sub(tst.in.Triangles[i].Vertices[j].Vec3()).Len()
+					if d > tst.tolerance {
+						t.Errorf("got vertex %d of triangle %d moved by %g; want at most %g", j, i, d, tst.tolerance)
+					}
+				}
+			}
+		})
+	}
+}
+func TestIndexedMesh_WeldNearest(t *testing.T) {
+	m := IndexedMesh{
+		Vertices: []Coordinate{{X: 0}, {X: 1}, {X: 0.9}, {X: 0.2}},
+		Faces:    []Face{{Vertices: [3]int{0, 1, 2}}, {Vertices: [3]int{3, 2, 1}}},
+	}
+
+	// 0.9 welds into 1 and 0.2 into 0, leaving two degenerate faces
+	if merged := m.Weld(0.25); merged != 2 {
+		t.Errorf("got %d merged; want 2", merged)
+	}
+	want := []Face{{Vertices: [3]int{0, 1, 1}}, {Vertices: [3]int{0, 1, 1}}}
+	for i := range want {
+		if m.Faces[i] != want[i] {
+			t.Errorf("got face %+v; want %+v", m.Faces[i], want[i])
+		}
+	}
+}
@@ -0,0 +1,47 @@
+package stl
+
+// ComputeNormal is the unit normal given by the winding of the vertices, using the right-hand rule.
+// A degenerate Triangle with no area has a zero normal.
+func (t Triangle) ComputeNormal() UnitVector {
+	a, b, c := t.Vertices[0].Vec3(), t.Vertices[1].Vec3(), t.Vertices[2].Vec3()
+	return b.Sub(a).Cross(c.Sub(a)).Normalize().UnitVector()
+}
+
+// RecomputeNormals replaces every Triangle normal with the one given by its winding
+func (s *Solid) RecomputeNormals() {
+	for i := range s.Triangles {
+		s.Triangles[i].Normal = s.Triangles[i].ComputeNormal()
+	}
+}
+
+// BadNormals returns the index of every Triangle whose stored normal is further than
+// tolerance from the unit normal given by its winding.
+// This finds normals that are zero, not unit length, or pointing the wrong way.
+// A tolerance of 1e-3 allows for float32 rounding.
+// Degenerate Triangles have no normal to compare with, so they are never reported.
+func (s *Solid) BadNormals(tolerance float64) []int {
+	var bad []int
+	for i, t := range s.Triangles {
+		want := t.ComputeNormal().Vec3()
+		if want == (Vec3{}) {
+			continue
+		}
+		if t.Normal.Vec3().Sub(want).Len() > tolerance {
+			bad = append(bad, i)
+		}
+	}
+
+	return bad
+}
+
+// recomputingNormals wraps a parse func to replace the normals of every Triangle it parses
+func recomputingNormals(parse func(chunk) (parsedChunk, error)) func(chunk) (parsedChunk, error) {
+	return func(c chunk) (parsedChunk, error) {
+		pc, err := parse(c)
+		for i := range pc.tris {
+			pc.tris[i].Normal = pc.tris[i].ComputeNormal()
+		}
+
+		return pc, err
+	}
+}
@@ -0,0 +1,129 @@
+package stl
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+)
+
+func TestTriangle_ComputeNormal(t *testing.T) {
+	tri := Triangle{Vertices: [3]Coordinate{{}, {X: 2}, {Y: 3}}}
+	if got := tri.ComputeNormal(); got != (UnitVector{Nk: 1}) {
+		t.Errorf("got %+v; want {0 0 1}", got)
+	}
+
+	degenerate := Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 2}}}
+	if got := degenerate.ComputeNormal(); got != (UnitVector{}) {
+		t.Errorf("got %+v for degenerate triangle; want zero", got)
+	}
+}
+func TestSolid_BadNormals(t *testing.T) {
+	s := testBox(1, 2, 3)
+	// Zero
+	s.Triangles[1].Normal = UnitVector{}
+	// Not unit length
+	s.Triangles[3].Normal = UnitVector{Nk: 2}
+	// Flipped
+	s.Triangles[5].Normal = UnitVector{Nj: 1}
+	// Slightly off, but within tolerance
+	s.Triangles[7].Normal = UnitVector{Ni: 0.0001, Nj: 1}
+	// Degenerate triangles are skipped
+	s.Triangles = append(s.Triangles, Triangle{Normal: UnitVector{Ni: 5}})
+
+	got := s.BadNormals(1e-3)
+	want := []int{1, 3, 5}
+	if len(got) != len(want) {
+		t.Fatalf("got %v; want %v", got, want)
+	}
+	for i := range got {
+		if got[i] != want[i] {
+			t.Errorf("got %v; want %v", got, want)
+		}
+	}
+
+	s.RecomputeNormals()
+	if got := s.BadNormals(1e-6); len(got) != 0 {
+		t.Errorf("got %v after recomputing; want none", got)
+	}
+}
+func TestSolid_RecomputeNormalsOnReadAndWrite(t *testing.T) {
+	s := testBox(1, 2, 3)
+	for i := range s.Triangles {
+		s.Triangles[i].Normal = UnitVector{}
+	}
+
+	// On write
+	buf := &bytes.Buffer{}
+	e, err := NewBinaryEncoder(buf, s.Header, s.TriangleCount)
+	if err != nil {
+		t.Fatalf("could not create encoder: %v", err)
+	}
+	e.RecomputeNormals = true
+	if err := e.WriteTriangles(s.Triangles); err != nil {
+		t.Fatalf("could not write triangles: %v", err)
+	}
+	if err := e.Close(); err != nil {
+		t.Fatalf("could not close encoder: %v", err)
+	}
+	written, err := From(bytes.NewReader(buf.Bytes()))
+	if err != nil {
+		t.Fatalf("could not read solid: %v", err)
+	}
+	if got := written.BadNormals(1e-6); len(got) != 0 {
+		t.Errorf("got bad normals %v after writing; want none", got)
+	}
+
+	// On write of a whole Solid, which is left unchanged
+	for name, write := range map[string]func(io.Writer, WriteOptions) error{
+		"ASCII":  s.ToASCIIWithOptions,
+		"binary": s.ToBinaryWithOptions,
+	} {
+		buf.Reset()
+		if err := write(buf, WriteOptions{RecomputeNormals: true}); err != nil {
+			t.Fatalf("could not write %s solid: %v", name, err)
+		}
+		written, err := From(bytes.NewReader(buf.Bytes()))
+		if err != nil {
+			t.Fatalf("could not read %s solid: %v", name, err)
+		}
+		if got := written.BadNormals(1e-6); len(got) != 0 {
+			t.Errorf("got bad normals %v after writing %s; want none", got, name)
+		}
+	}
+	if s.Triangles[0].Normal != (UnitVector{}) {
+		t.Errorf("got normal %v changed by writing", s.Triangles[0].Normal)
+	}
+
+	// On read
+	buf.Reset()
+	if err := s.ToASCII(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	read, err := FromContext(context.Background(), buf, ReadOptions{RecomputeNormals: true})
+	if err != nil {
+		t.Fatalf("could not read solid: %v", err)
+	}
+	if got := read.BadNormals(1e-6); len(got) != 0 {
+		t.Errorf("got bad normals %v after reading; want none", got)
+	}
+
+	// On read one Triangle at a time
+	buf.Reset()
+	if err := s.ToBinary(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	d, err := NewDecoder(buf)
+	if err != nil {
+		t.Fatalf("could not create decoder: %v", err)
+	}
+	d.RecomputeNormals = true
+	for tri, err := range d.All() {
+		if err != nil {
+			t.Fatalf("could not read triangle: %v", err)
+		}
+		if tri.Normal != tri.ComputeNormal() || tri.Normal == (UnitVector{}) {
+			t.Errorf("got normal %v after decoding; want %v", tri.Normal, tri.ComputeNormal())
+		}
+	}
+}
@@ -0,0 +1,257 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// source is the body of an input, after its header, and how to split it into chunks
+type source struct {
+	r      io.Reader
+	split  bufio.SplitFunc
+	format Format
+	// facets is the number of facets in a chunk
+	facets func(raw []byte) int
+	// offset and line are where the body starts in the input
+	offset int64
+	line   int
+}
+
+// chunk is a piece of raw input and its position in the input
+type chunk struct {
+	idx    int
+	raw    []byte
+	offset int64
+	line   int
+	first  int
+	// last is set on the final chunk of the input
+	last bool
+}
+
+// parsedChunk is the parsed Triangles of a chunk and its position in the input
+type parsedChunk struct {
+	idx  int
+	tris []Triangle
+	// solids are where new solids start, relative to tris
+	solids []solidMark
+}
+
+// solidMark is the start of a solid after the first in a multi-solid input
+type solidMark struct {
+	// at is the index of the first Triangle of the solid
+	at   int
+	name string
+}
+
+// pipeline splits an input into chunks and parses them concurrently.
+// The first error, or cancellation of the parent context, stops every stage.
+type pipeline struct {
+	ctx     context.Context
+	cancel  context.CancelFunc
+	mu      sync.Mutex
+	err     error
+	workers int
+	maxTris int
+	maxScan int
+}
+
+func newPipeline(ctx context.Context, opts ReadOptions) *pipeline {
+	ctx, cancel := context.WithCancel(ctx)
+	return &pipeline{
+		ctx:     ctx,
+		cancel:  cancel,
+		workers: opts.Workers,
+		maxTris: opts.MaxTriangles,
+		maxScan: bufio.MaxScanTokenSize + opts.ChunkSize,
+	}
+}
+
+// fail records the first error and stops the pipeline
+func (p *pipeline) fail(err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.err == nil {
+		p.err = err
+		p.cancel()
+	}
+}
+
+// firstErr is the error that stopped the pipeline, if any
+func (p *pipeline) firstErr() error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	return p.err
+}
+
+// run splits src into chunks and parses each chunk with parse on its own worker.
+// Triangles are returned in input order, along with where any new solids start.
+// sizeHint is the expected number of Triangles.
+func (p *pipeline) run(src source, parse func(chunk) (parsedChunk, error), sizeHint int) ([]Triangle, []solidMark, error) {
+	defer p.cancel()
+
+	// Read in data.  Put on work chan raw.
+	raw := p.send(src)
+
+	// Start up workers
+	parsed := make(chan parsedChunk)
+	wg := &sync.WaitGroup{}
+	for i := 0; i < p.workers; i++ {
+		wg.Add(1)
+		go p.parse(raw, parsed, parse, wg)
+	}
+
+	// When workers are done, close chan
+	go func() {
+		wg.Wait()
+		close(parsed)
+	}()
+
+	// Accumulate parsed Triangles until parsed channel is closed
+	tris, solids := p.collect(parsed, sizeHint)
+
+	if err := p.firstErr(); err != nil {
+		return nil, nil, err
+	}
+	if err := p.ctx.Err(); err != nil {
+		return nil, nil, err
+	}
+
+	return tris, solids, nil
+}
+func (p *pipeline) send(src source) <-chan chunk {
+	raw := make(chan chunk)
+
+	go func() {
+		defer close(raw)
+
+		// Create Scanner with split func for chunks of triangles
+		pos := &position{offset: src.offset, line: src.line, countLines: src.format == FormatASCII}
+		scanner := bufio.NewScanner(src.r)
+		scanner.Buffer(nil, p.maxScan)
+		scanner.Split(pos.track(src.split))
+
+		// Need to copy each read from the Scanner because it will be overwritten by the next Scan
+		// Each chunk is tagged with its position so the collector can restore input order
+		facet := 0
+		for idx := 0; scanner.Scan(); idx++ {
+			bin := make([]byte, len(scanner.Bytes()))
+			copy(bin, scanner.Bytes())
+
+			c := chunk{idx: idx, raw: bin, offset: pos.tokenOffset, line: pos.tokenLine, first: facet, last: pos.tokenLast}
+			facet += src.facets(bin)
+
+			select {
+			case raw <- c:
+			case <-p.ctx.Done():
+				return
+			}
+		}
+
+		if scanner.Err() != nil {
+			p.fail(pos.errorAt(src.format, facet, scanner.Err()))
+		}
+	}()
+
+	return raw
+}
+func (p *pipeline) parse(raw <-chan chunk, parsed chan<- parsedChunk, parse func(chunk) (parsedChunk, error), wg *sync.WaitGroup) {
+	defer wg.Done()
+
+	for c := range raw {
+		pc, err := parse(c)
+		if err != nil {
+			p.fail(err)
+			return
+		}
+		pc.idx = c.idx
+
+		select {
+		case parsed <- pc:
+		case <-p.ctx.Done():
+			return
+		}
+	}
+}
+func (p *pipeline) collect(parsed <-chan parsedChunk, sizeHint int) ([]Triangle, []solidMark) {
+	// Workers finish out of order, so hold each chunk at its index until all are in
+	var chunks []parsedChunk
+	cnt := 0
+	for c := range parsed {
+		for len(chunks) <= c.idx {
+			chunks = append(chunks, parsedChunk{})
+		}
+		chunks[c.idx] = c
+
+		cnt += len(c.tris)
+		if p.maxTris > 0 && cnt > p.maxTris {
+			p.fail(fmt.Errorf("%w: more than %d", ErrTooManyTriangles, p.maxTris))
+		}
+	}
+
+	if p.firstErr() != nil {
+		return nil, nil
+	}
+
+	tris := make([]Triangle, 0, max(sizeHint, cnt))
+	var solids []solidMark
+	for _, c := range chunks {
+		for _, s := range c.solids {
+			solids = append(solids, solidMark{at: len(tris) + s.at, name: s.name})
+		}
+		tris = append(tris, c.tris...)
+	}
+
+	return tris, solids
+}
+
+// position tracks how far a split func has advanced through the input
+type position struct {
+	offset     int64
+	line       int
+	countLines bool
+	// tokenOffset and tokenLine are where the last token returned started
+	tokenOffset int64
+	tokenLine   int
+	// tokenLast is set when the last token returned was the final one
+	tokenLast bool
+}
+
+// track wraps split so every advance is counted
+func (p *position) track(split bufio.SplitFunc) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (int, []byte, error) {
+		advance, token, err := split(data, atEOF)
+		if token != nil {
+			p.tokenOffset, p.tokenLine = p.offset, p.line
+			p.tokenLast = err == bufio.ErrFinalToken
+		}
+
+		p.offset += int64(advance)
+		if p.countLines {
+			p.line += bytes.Count(data[:advance], []byte{'\n'})
+		}
+
+		return advance, token, err
+	}
+}
+
+// errorAt is err located at the current position.
+// Cancellation is returned as is.
+func (p *position) errorAt(format Format, facet int, err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return err
+	}
+
+	line := 0
+	if p.countLines {
+		line = p.line
+	}
+
+	return &ParseError{Format: format, Line: line, Facet: facet, Offset: p.offset, Err: err}
+}
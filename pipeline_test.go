@@ -0,0 +1,71 @@
+package stl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func Test_pipelineCollectOrder(t *testing.T) {
+	p := newPipeline(context.Background(), ReadOptions{}.withDefaults())
+	parsed := make(chan parsedChunk, 3)
+
+	// Deliver chunks out of order
+	for _, c := range []parsedChunk{
+		{idx: 1, tris: []Triangle{{AttrByteCnt: 2}, {AttrByteCnt: 3}}, solids: []solidMark{{at: 1, name: "b"}}},
+		{idx: 2, tris: []Triangle{{AttrByteCnt: 4}}, solids: []solidMark{{at: 0, name: "c"}}},
+		{idx: 0, tris: []Triangle{{AttrByteCnt: 0}, {AttrByteCnt: 1}}},
+	} {
+		parsed <- c
+	}
+	close(parsed)
+
+	got, solids := p.collect(parsed, 0)
+	if len(got) != 5 {
+		t.Fatalf("got %d triangles; want 5", len(got))
+	}
+	for i, tri := range got {
+		if tri.AttrByteCnt != uint16(i) {
+			t.Errorf("got triangle %d at index %d; want %d", tri.AttrByteCnt, i, i)
+		}
+	}
+
+	// Solids start relative to the whole input
+	want := []solidMark{{at: 3, name: "b"}, {at: 4, name: "c"}}
+	if len(solids) != len(want) || solids[0] != want[0] || solids[1] != want[1] {
+		t.Errorf("got solids %v; want %v", solids, want)
+	}
+}
+func Test_pipelineFirstError(t *testing.T) {
+	before := runtime.NumGoroutine()
+
+	// Many chunks with a failure early on, so the producer is still sending when a worker fails
+	data := bytes.Repeat(make([]byte, 50), 20000)
+	want := errors.New("bad chunk")
+	parse := func(c chunk) (parsedChunk, error) {
+		return parsedChunk{}, want
+	}
+	src := source{
+		r:      bytes.NewReader(data),
+		split:  splitTrianglesBinary(defaultChunkSize, false),
+		format: FormatBinary,
+		facets: func(raw []byte) int { return len(raw) / 50 },
+	}
+
+	p := newPipeline(context.Background(), ReadOptions{}.withDefaults())
+	if _, _, err := p.run(src, parse, 0); err != want {
+		t.Errorf("got %v; want %v", err, want)
+	}
+
+	// All goroutines should exit once the pipeline has stopped
+	deadline := time.Now().Add(time.Second)
+	for runtime.NumGoroutine() > before && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if after := runtime.NumGoroutine(); after > before {
+		t.Errorf("got %d goroutines after error; want %d", after, before)
+	}
+}
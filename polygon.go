@@ -0,0 +1,127 @@
+package stl
+
+import "math"
+
+// Vec2 is a point or direction in a plane, in float64 for calculations
+type Vec2 struct {
+	X, Y float64
+}
+
+// Add is a + b
+func (a Vec2) Add(b Vec2) Vec2 {
+	return Vec2{a.X + b.X, a.Y + b.Y}
+}
+
+// Sub is a - b
+func (a Vec2) Sub(b Vec2) Vec2 {
+	return Vec2{a.X - b.X, a.Y - b.Y}
+}
+
+// Scale is a * f
+func (a Vec2) Scale(f float64) Vec2 {
+	return Vec2{a.X * f, a.Y * f}
+}
+
+// Dot is the dot product of a and b
+func (a Vec2) Dot(b Vec2) float64 {
+	return a.X*b.X + a.Y*b.Y
+}
+
+// Cross is the z component of the cross product of a and b, positive when b is counter-clockwise from a
+func (a Vec2) Cross(b Vec2) float64 {
+	return a.X*b.Y - a.Y*b.X
+}
+
+// Len is the length of a
+func (a Vec2) Len() float64 {
+	return math.Hypot(a.X, a.Y)
+}
+
+// Polygon is a closed loop of points.  The last point joins back to the first.
+type Polygon []Vec2
+
+// Area is the signed area of the Polygon, positive when it runs counter-clockwise
+func (p Polygon) Area() float64 {
+	a := 0.0
+	for i := range p {
+		a += p[i].Cross(p[(i+1)%len(p)])
+	}
+
+	return a / 2
+}
+
+// Hole reports whether the Polygon runs clockwise, which marks a hole in a Slice
+func (p Polygon) Hole() bool {
+	return p.Area() < 0
+}
+
+// Perimeter is the length of the Polygon's edges
+func (p Polygon) Perimeter() float64 {
+	l := 0.0
+	for i := range p {
+		l += p[(i+1)%len(p)].Sub(p[i]).Len()
+	}
+
+	return l
+}
+
+// Contains reports whether q is inside the Polygon, by the even-odd rule.
+// Points on an edge may be either inside or outside.
+func (p Polygon) Contains(q Vec2) bool {
+	inside := false
+	for i := range p {
+		a, b := p[i], p[(i+1)%len(p)]
+		if (a.Y > q.Y) != (b.Y > q.Y) && q.X < a.X+(q.Y-a.Y)*(b.X-a.X)/(b.Y-a.Y) {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// Reverse returns the Polygon running the other way
+func (p Polygon) Reverse() Polygon {
+	r := make(Polygon, len(p))
+	for i, v := range p {
+		r[len(p)-1-i] = v
+	}
+
+	return r
+}
+
+// Region is an outer boundary and the holes inside it
+type Region struct {
+	Outer Polygon
+	Holes []Polygon
+}
+
+// Regions pairs each hole with the smallest outer boundary holding it.
+// Holes that no outer boundary holds are left out.
+func Regions(polys []Polygon) []Region {
+	var regions []Region
+	var areas []float64
+	for _, p := range polys {
+		if !p.Hole() {
+			regions = append(regions, Region{Outer: p})
+			areas = append(areas, p.Area())
+		}
+	}
+
+	for _, p := range polys {
+		if !p.Hole() || len(p) == 0 {
+			continue
+		}
+
+		best := -1
+		for i, r := range regions {
+			if r.Outer.Contains(p[0]) && (best < 0 || areas[i] < areas[best]) {
+				best = i
+			}
+		}
+		if best >= 0 {
+			regions[best].Holes = append(regions[best].Holes, p)
+		}
+	}
+
+	return regions
+}
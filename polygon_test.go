@@ -0,0 +1,77 @@
+package stl
+
+import "testing"
+
+func TestPolygon(t *testing.T) {
+	square := Polygon{{0, 0}, {2, 0}, {2, 2}, {0, 2}}
+
+	for _, tst := range []struct {
+		name          string
+		in            Polygon
+		wantArea      float64
+		wantPerimeter float64
+		wantHole      bool
+	}{
+		{
+			name:          "counter-clockwise",
+			in:            square,
+			wantArea:      4,
+			wantPerimeter: 8,
+		},
+		{
+			name:          "clockwise",
+			in:            square.Reverse(),
+			wantArea:      -4,
+			wantPerimeter: 8,
+			wantHole:      true,
+		},
+		{
+			name: "empty",
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			if got := tst.in.Area(); !near(got, tst.wantArea) {
+				t.Errorf("got area %v; want %v", got, tst.wantArea)
+			}
+			if got := tst.in.Perimeter(); !near(got, tst.wantPerimeter) {
+				t.Errorf("got perimeter %v; want %v", got, tst.wantPerimeter)
+			}
+			if got := tst.in.Hole(); got != tst.wantHole {
+				t.Errorf("got hole %t; want %t", got, tst.wantHole)
+			}
+		})
+	}
+
+	for _, tst := range []struct {
+		q    Vec2
+		want bool
+	}{
+		{q: Vec2{1, 1}, want: true},
+		{q: Vec2{3, 1}},
+		{q: Vec2{1, -1}},
+	} {
+		if got := square.Contains(tst.q); got != tst.want {
+			t.Errorf("got %v inside %t; want %t", tst.q, got, tst.want)
+		}
+	}
+}
+func TestRegions(t *testing.T) {
+	outer := Polygon{{0, 0}, {10, 0}, {10, 10}, {0, 10}}
+	hole := Polygon{{2, 2}, {8, 2}, {8, 8}, {2, 8}}.Reverse()
+	island := Polygon{{4, 4}, {6, 4}, {6, 6}, {4, 6}}
+	islandHole := Polygon{{4.5, 4.5}, {5.5, 4.5}, {5.5, 5.5}, {4.5, 5.5}}.Reverse()
+	stray := Polygon{{20, 20}, {21, 20}, {21, 21}}.Reverse()
+
+	r := Regions([]Polygon{islandHole, outer, hole, island, stray})
+	if len(r) != 2 {
+		t.Fatalf("got %d regions; want 2", len(r))
+	}
+	if len(r[0].Holes) != 1 || r[0].Holes[0][0] != hole[0] {
+		t.Errorf("got outer holes %v; want %v", r[0].Holes, hole)
+	}
+	if len(r[1].Holes) != 1 || r[1].Holes[0][0] != islandHole[0] {
+		t.Errorf("got island holes %v; want %v", r[1].Holes, islandHole)
+	}
+}
@@ -0,0 +1,216 @@
+package stl
+
+import (
+	"bufio"
+	"fmt"
+	"image"
+	"image/png"
+	"io"
+	"iter"
+	"math"
+	"sort"
+)
+
+// Rows of samples taken in each pixel by default
+const defaultRasterSamples = 4
+
+// FillRule decides which areas enclosed by Polygons are filled
+type FillRule int
+
+// Fill rules
+const (
+	// FillNonZero fills areas that Polygons wind around a non-zero number of times,
+	// so overlapping boundaries are filled and clockwise holes are not
+	FillNonZero FillRule = iota
+	// FillEvenOdd fills areas inside an odd number of Polygons, whichever way they run
+	FillEvenOdd
+)
+
+// String is the name of the FillRule as used by SVG
+func (f FillRule) String() string {
+	switch f {
+	case FillNonZero:
+		return "nonzero"
+	case FillEvenOdd:
+		return "evenodd"
+	default:
+		return "unknown"
+	}
+}
+
+// Raster maps Slices onto images, such as the layer masks of a resin printer.
+// Material is white and empty space is black.  The image looks down on the plane, so
+// its top row is at the highest Y.
+type Raster struct {
+	// Origin is the point in the plane at the bottom left corner of the image
+	Origin Vec2
+	// PixelWidth and PixelHeight are the size of a pixel along X and Y, in the units
+	// of the Solid.  They differ for panels with pixels that are not square.
+	PixelWidth, PixelHeight float64
+	// Width and Height are the size of the image in pixels
+	Width, Height int
+	FillRule      FillRule
+	// Samples is the rows sampled in each pixel for anti-aliasing.  Zero is 4, and 1 turns it off.
+	// Coverage across each row is exact.
+	Samples int
+}
+
+// FitRaster is a Raster just holding the BoundingBox of the Solid seen from above,
+// with pixels pixelWidth by pixelHeight.  Set Width, Height and Origin instead to match a printer.
+func (s *Solid) FitRaster(pixelWidth, pixelHeight float64) Raster {
+	r := Raster{PixelWidth: pixelWidth, PixelHeight: pixelHeight}
+	if pixelWidth <= 0 || pixelHeight <= 0 || len(s.Triangles) == 0 {
+		return r
+	}
+
+	box := s.BoundingBox()
+	r.Origin = Vec2{box.Min.X, box.Min.Y}
+	r.Width = max(1, int(math.Ceil((box.Max.X-box.Min.X)/pixelWidth)))
+	r.Height = max(1, int(math.Ceil((box.Max.Y-box.Min.Y)/pixelHeight)))
+
+	return r
+}
+
+// Layers slices the Solid along Z every layerHeight, as in Solid.SliceLayers, and
+// yields each Slice with its image
+func (r Raster) Layers(s *Solid, layerHeight float64) iter.Seq2[Slice, *image.Gray] {
+	return func(yield func(Slice, *image.Gray) bool) {
+		for _, sl := range s.SliceLayers(AxisZ, layerHeight) {
+			if !yield(sl, r.Image(sl)) {
+				return
+			}
+		}
+	}
+}
+
+// Image draws the Polygons of the Slice, with each pixel as gray as the fraction of it covered
+func (r Raster) Image(sl Slice) *image.Gray {
+	img := image.NewGray(image.Rect(0, 0, max(0, r.Width), max(0, r.Height)))
+	if r.Width <= 0 || r.Height <= 0 || r.PixelWidth <= 0 || r.PixelHeight <= 0 {
+		return img
+	}
+
+	samples := r.Samples
+	if samples <= 0 {
+		samples = defaultRasterSamples
+	}
+
+	polys := r.pixels(sl.Polygons)
+	cover := make([]float64, r.Width)
+	for y := 0; y < r.Height; y++ {
+		clear(cover)
+		for k := 0; k < samples; k++ {
+			r.fillRow(polys, float64(y)+(float64(k)+0.5)/float64(samples), cover)
+		}
+
+		row := img.Pix[y*img.Stride : y*img.Stride+r.Width]
+		for x := range row {
+			row[x] = uint8(math.Round(255 * min(1, cover[x]/float64(samples))))
+		}
+	}
+
+	return img
+}
+
+// WritePNG writes the Image of the Slice as a grayscale PNG
+func (r Raster) WritePNG(w io.Writer, sl Slice) error {
+	if err := png.Encode(w, r.Image(sl)); err != nil {
+		return fmt.Errorf("did not write png: %w", err)
+	}
+
+	return nil
+}
+
+// WriteSVG writes the Polygons of the Slice as an SVG path, in pixels of the Raster
+func (r Raster) WriteSVG(w io.Writer, sl Slice) error {
+	bw := bufio.NewWriter(w)
+
+	fmt.Fprintf(bw, "<svg xmlns=\"http://www.w3.org/2000/svg\" width=\"%d\" height=\"%d\" viewBox=\"0 0 %d %d\">\n", r.Width, r.Height, r.Width, r.Height)
+	fmt.Fprintf(bw, "<rect width=\"%d\" height=\"%d\" fill=\"black\"/>\n", r.Width, r.Height)
+	fmt.Fprintf(bw, "<path fill=\"white\" fill-rule=\"%s\" d=\"", r.FillRule)
+	for i, p := range r.pixels(sl.Polygons) {
+		if i > 0 {
+			bw.WriteByte(' ')
+		}
+		for j, q := range p {
+			cmd := 'L'
+			if j == 0 {
+				cmd = 'M'
+			}
+			fmt.Fprintf(bw, "%c%.3f %.3f ", cmd, q.X, q.Y)
+		}
+		bw.WriteByte('Z')
+	}
+	bw.WriteString("\"/>\n</svg>\n")
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write svg: %w", err)
+	}
+
+	return nil
+}
+
+// pixels converts Polygons to pixel coordinates, with Y running down the image
+func (r Raster) pixels(polys []Polygon) []Polygon {
+	out := make([]Polygon, len(polys))
+	for i, p := range polys {
+		out[i] = make(Polygon, len(p))
+		for j, q := range p {
+			out[i][j] = Vec2{
+				X: (q.X - r.Origin.X) / r.PixelWidth,
+				Y: float64(r.Height) - (q.Y-r.Origin.Y)/r.PixelHeight,
+			}
+		}
+	}
+
+	return out
+}
+
+// rowCrossing is where an edge crosses a row, and which way it winds
+type rowCrossing struct {
+	x    float64
+	wind int
+}
+
+// fillRow adds how much of each pixel is filled along the row at y to cover
+func (r Raster) fillRow(polys []Polygon, y float64, cover []float64) {
+	var xs []rowCrossing
+	for _, p := range polys {
+		for i := range p {
+			a, b := p[i], p[(i+1)%len(p)]
+			if (a.Y <= y) == (b.Y <= y) {
+				continue
+			}
+
+			wind := 1
+			if b.Y < a.Y {
+				wind = -1
+			}
+			xs = append(xs, rowCrossing{x: a.X + (y-a.Y)*(b.X-a.X)/(b.Y-a.Y), wind: wind})
+		}
+	}
+	sort.Slice(xs, func(i, j int) bool { return xs[i].x < xs[j].x })
+
+	wind := 0
+	for i := 0; i+1 < len(xs); i++ {
+		wind += xs[i].wind
+		inside := wind != 0
+		if r.FillRule == FillEvenOdd {
+			inside = (i+1)%2 == 1
+		}
+		if inside {
+			coverSpan(cover, xs[i].x, xs[i+1].x)
+		}
+	}
+}
+
+// coverSpan adds the part of each pixel between x0 and x1 to cover
+func coverSpan(cover []float64, x0, x1 float64) {
+	x0, x1 = max(0, x0), min(float64(len(cover)), x1)
+	for x0 < x1 {
+		px := int(x0)
+		end := min(x1, float64(px+1))
+		cover[px] += end - x0
+		x0 = end
+	}
+}
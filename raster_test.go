@@ -0,0 +1,142 @@
+package stl
+
+import (
+	"bytes"
+	"image/png"
+	"strings"
+	"testing"
+)
+
+// coverage is the sum of the pixels of the Image, as a fraction of full pixels
+func coverage(r Raster, sl Slice) float64 {
+	sum := 0
+	for _, p := range r.Image(sl).Pix {
+		sum += int(p)
+	}
+
+	return float64(sum) / 255
+}
+func TestRaster_Image(t *testing.T) {
+	box := testBox(1, 2, 3)
+	r := box.FitRaster(0.1, 0.1)
+	if r.Width != 10 || r.Height != 20 {
+		t.Fatalf("got %dx%d raster; want 10x20", r.Width, r.Height)
+	}
+
+	sl := box.Slice(Plane{Point: Vec3{Z: 1}, Normal: Vec3{Z: 1}})
+	img := r.Image(sl)
+	for i, p := range img.Pix {
+		if p != 255 {
+			t.Fatalf("got pixel %d at %d; want 255", i, p)
+		}
+	}
+
+	// Pixels that are not square fit the same box in fewer rows
+	wide := box.FitRaster(0.1, 0.25)
+	if wide.Width != 10 || wide.Height != 8 {
+		t.Fatalf("got %dx%d raster; want 10x8", wide.Width, wide.Height)
+	}
+	if got := coverage(wide, sl); !near(got, 80) {
+		t.Errorf("got coverage %v; want 80", got)
+	}
+
+	// Shifting half a pixel leaves the edges half covered
+	half := r
+	half.Origin = Vec2{-0.05, -0.05}
+	img = half.Image(sl)
+	if got := img.GrayAt(0, 19).Y; got != 64 {
+		t.Errorf("got corner %d; want 64", got)
+	}
+	if got := img.GrayAt(0, 10).Y; got != 128 {
+		t.Errorf("got edge %d; want 128", got)
+	}
+	if got := img.GrayAt(5, 10).Y; got != 255 {
+		t.Errorf("got middle %d; want 255", got)
+	}
+
+	// The top of the image is the highest Y
+	tall := testBox(1, 1, 1)
+	tall.Transform(Translate(0, 1, 0))
+	frame := Raster{PixelWidth: 0.1, PixelHeight: 0.1, Width: 10, Height: 20}
+	img = frame.Image(tall.Slice(Plane{Point: Vec3{Z: 0.5}, Normal: Vec3{Z: 1}}))
+	if img.GrayAt(5, 5).Y != 255 || img.GrayAt(5, 15).Y != 0 {
+		t.Errorf("got top %d, bottom %d; want 255, 0", img.GrayAt(5, 5).Y, img.GrayAt(5, 15).Y)
+	}
+}
+func TestRaster_FillRule(t *testing.T) {
+	// Two boxes overlapping by half
+	a := testBox(2, 2, 2)
+	b := testBox(2, 2, 2)
+	b.Transform(Translate(1, 0, 0))
+	overlap := Merge(a, b)
+	r := Raster{PixelWidth: 0.1, PixelHeight: 0.1, Width: 30, Height: 20}
+
+	void := boxWithVoid(false)
+	vr := void.FitRaster(0.1, 0.1)
+
+	for _, tst := range []struct {
+		name string
+		r    Raster
+		in   Solid
+		z    float64
+		want float64
+	}{
+		{name: "overlap nonzero", r: r, in: overlap, z: 1, want: 600},
+		{name: "overlap evenodd", r: Raster{PixelWidth: 0.1, PixelHeight: 0.1, Width: 30, Height: 20, FillRule: FillEvenOdd}, in: overlap, z: 1, want: 400},
+		{name: "void nonzero", r: vr, in: void, z: 2, want: 1200},
+		{name: "void evenodd", r: Raster{PixelWidth: 0.1, PixelHeight: 0.1, Width: 40, Height: 40, FillRule: FillEvenOdd}, in: void, z: 2, want: 1200},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			sl := tst.in.Slice(Plane{Point: Vec3{Z: tst.z}, Normal: Vec3{Z: 1}})
+			if got := coverage(tst.r, sl); !near(got, tst.want) {
+				t.Errorf("got %v pixels covered; want %v", got, tst.want)
+			}
+		})
+	}
+}
+func TestRaster_Layers(t *testing.T) {
+	box := testBox(1, 2, 3)
+	r := box.FitRaster(0.5, 0.5)
+
+	n := 0
+	for sl, img := range r.Layers(&box, 1) {
+		if img.Bounds().Dx() != 2 || img.Bounds().Dy() != 4 || len(sl.Polygons) != 1 {
+			t.Errorf("got layer %d of %v with %d polygons; want 2x4, 1", n, img.Bounds(), len(sl.Polygons))
+		}
+		n++
+	}
+	if n != 3 {
+		t.Errorf("got %d layers; want 3", n)
+	}
+}
+func TestRaster_Write(t *testing.T) {
+	box := testBox(1, 2, 3)
+	r := box.FitRaster(0.1, 0.1)
+	r.FillRule = FillEvenOdd
+	sl := box.Slice(Plane{Point: Vec3{Z: 1}, Normal: Vec3{Z: 1}})
+
+	var buf bytes.Buffer
+	if err := r.WritePNG(&buf, sl); err != nil {
+		t.Fatalf("could not write png: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("could not read png: %v", err)
+	}
+	if img.Bounds().Dx() != 10 || img.Bounds().Dy() != 20 {
+		t.Errorf("got png %v; want 10x20", img.Bounds())
+	}
+
+	buf.Reset()
+	if err := r.WriteSVG(&buf, sl); err != nil {
+		t.Fatalf("could not write svg: %v", err)
+	}
+	svg := buf.String()
+	for _, want := range []string{`width="10" height="20"`, `fill-rule="evenodd"`, `d="M`, "Z\"/>", "</svg>"} {
+		if !strings.Contains(svg, want) {
+			t.Errorf("got svg %q; want it to contain %q", svg, want)
+		}
+	}
+}
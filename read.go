@@ -2,30 +2,166 @@ package stl
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
 	"os"
+	"runtime"
 	"strings"
 )
 
+// Defaults used for zero values in ReadOptions
+const (
+	defaultChunkSize  = 50000
+	defaultBufferSize = 4096
+)
+
+// ReadOptions controls how input is read.
+// The zero value auto-detects the format, uses every CPU, and has no limits.
+type ReadOptions struct {
+	// MaxTriangles is the most Triangles allowed in the input.  Zero is no limit.
+	MaxTriangles int
+	// MaxBytes is the most bytes read from the input.  Zero is no limit.
+	MaxBytes int64
+	// Workers is the number of goroutines parsing the input.  Zero is runtime.NumCPU().
+	Workers int
+	// ChunkSize is the number of bytes handed to a worker at a time.  Zero is 50000.
+	// Binary input is rounded down to a whole number of 50 byte triangles, and
+	// ASCII input is rounded to the nearest whole facet.
+	ChunkSize int
+	// BufferSize is the size of the buffer used to read the input.  Zero is 4096.
+	// Sizes below 84 are raised to 84 so the whole binary header can be inspected.
+	BufferSize int
+	// Format skips detection and reads the input as the given format.
+	// FormatUnknown detects it.
+	Format Format
+	// Lenient accepts input with recoverable defects instead of returning an error.
+	// A partial triangle at the end of binary input is dropped.  ASCII input may
+	// be missing "endsolid", or have more after it.  A binary triangle count that
+	// does not match the data is replaced by the number of triangles read.
+	Lenient bool
+	// IgnoreTrailing reads only the number of triangles declared in a binary header,
+	// ignoring any bytes after them such as the padding some exporters append.
+	IgnoreTrailing bool
+	// StrictCount returns an ErrCountMismatch when a binary triangle count does not
+	// match the data, unless Lenient is also set.  Otherwise the mismatch is only
+	// passed to OnWarning, and TriangleCount keeps the declared count.
+	StrictCount bool
+	// RecomputeNormals replaces the normal of every Triangle with the one given by
+	// its winding.  See Solid.RecomputeNormals
+	RecomputeNormals bool
+	// OnWarning is called with each defect in binary input that was accepted,
+	// such as a triangle count that does not match the data.  It receives a
+	// *ParseError, the same as would be returned otherwise.
+	OnWarning func(error)
+}
+
+// withDefaults replaces zero values with their defaults
+func (o ReadOptions) withDefaults() ReadOptions {
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultChunkSize
+	}
+	if o.BufferSize <= 0 {
+		o.BufferSize = defaultBufferSize
+	}
+
+	return o
+}
+
+// warn reports a defect that was accepted
+func (o ReadOptions) warn(err error) {
+	if o.OnWarning != nil {
+		o.OnWarning(err)
+	}
+}
+
 // From creates a Solid from the input.
 // It handles both ASCII and binary formats.
 func From(r io.Reader) (s Solid, err error) {
-	// Use a buffered reader.  Default size is 4096 (4KB).
-	br := bufio.NewReader(r)
+	return FromContext(context.Background(), r, ReadOptions{})
+}
+
+// FromContext creates a Solid from the input, stopping all parsing when ctx is
+// cancelled or the first error is found.
+// Limits in opts are enforced while reading, so it is safe to use with untrusted input.
+// See stl.From for more info
+func FromContext(ctx context.Context, r io.Reader, opts ReadOptions) (Solid, error) {
+	opts = opts.withDefaults()
 
-	// Read first 6 bytes to get file type indicator.
-	indicator, err := br.Peek(6)
+	br, format, err := openInput(r, opts)
 	if err != nil {
-		return Solid{}, fmt.Errorf("input has no content")
+		return Solid{}, err
+	}
+
+	if format == FormatASCII {
+		return fromASCII(ctx, br, opts)
+	}
+
+	return fromBinary(ctx, br, opts)
+}
+
+// FromMulti creates a Solid for each "solid ... endsolid" block of ASCII input,
+// with the name of each block as its Header.
+// Binary input always holds a single Solid.
+// See stl.From for more info
+func FromMulti(r io.Reader) ([]Solid, error) {
+	return FromMultiContext(context.Background(), r, ReadOptions{})
+}
+
+// FromMultiContext is FromMulti with a context and ReadOptions.
+// See stl.FromContext for more info
+func FromMultiContext(ctx context.Context, r io.Reader, opts ReadOptions) ([]Solid, error) {
+	opts = opts.withDefaults()
+
+	br, format, err := openInput(r, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if format == FormatASCII {
+		return fromASCIISolids(ctx, br, opts, asciiRules{lenient: opts.Lenient, multi: true})
+	}
+
+	s, err := fromBinary(ctx, br, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Solid{s}, nil
+}
+
+// openInput wraps r to enforce opts and finds the format of the input
+func openInput(r io.Reader, opts ReadOptions) (*bufio.Reader, Format, error) {
+	// Size must be found before r is wrapped
+	size := inputSize(r)
+
+	if opts.MaxBytes > 0 {
+		if size > opts.MaxBytes {
+			return nil, FormatUnknown, fmt.Errorf("%w: %d bytes, limit is %d", ErrTooLarge, size, opts.MaxBytes)
+		}
+		r = &maxBytesReader{r: r, remaining: opts.MaxBytes}
 	}
 
-	// If indicator is "solid " then it is an ASCII file.  Otherwise binary.
-	if string(indicator) == "solid " {
-		return fromASCII(br)
+	// Use a buffered reader.  Default size is 4096 (4KB).
+	// Detection needs to see the 80 byte header and the triangle count.
+	br := bufio.NewReaderSize(r, max(opts.BufferSize, 84))
+
+	format := opts.Format
+	switch format {
+	case FormatUnknown:
+		var err error
+		if format, err = detectFormat(br, size); err != nil {
+			return nil, FormatUnknown, err
+		}
+	case FormatASCII, FormatBinary:
+	default:
+		return nil, FormatUnknown, fmt.Errorf("cannot read %s format as STL", format)
 	}
 
-	return fromBinary(br)
+	return br, format, nil
 }
 
 // FromFile creates a Solid from a file
@@ -40,3 +176,68 @@ func FromFile(filename string) (Solid, error) {
 
 	return From(file)
 }
+
+// FromFileContext creates a Solid from a file
+// See stl.FromContext for more info
+func FromFileContext(ctx context.Context, filename string, opts ReadOptions) (Solid, error) {
+	// Open file for reading
+	file, err := os.Open(strings.TrimSpace(filename))
+	if err != nil {
+		return Solid{}, err
+	}
+	defer file.Close()
+
+	return FromContext(ctx, file, opts)
+}
+
+// FromMultiFile creates a Solid for each solid in a file
+// See stl.FromMulti for more info
+func FromMultiFile(filename string) ([]Solid, error) {
+	// Open file for reading
+	file, err := os.Open(strings.TrimSpace(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return FromMulti(file)
+}
+
+// countingReader counts the bytes read through it
+type countingReader struct {
+	r io.Reader
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	c.n += int64(n)
+	return n, err
+}
+
+// maxBytesReader fails with ErrTooLarge once more than remaining bytes are available.
+// Unlike io.LimitReader, hitting the limit is an error rather than a silent EOF.
+type maxBytesReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (m *maxBytesReader) Read(p []byte) (int, error) {
+	// At the limit, any more data means the input is too large
+	if m.remaining <= 0 {
+		var b [1]byte
+		n, err := m.r.Read(b[:])
+		if n > 0 {
+			return 0, ErrTooLarge
+		}
+		return 0, err
+	}
+
+	if int64(len(p)) > m.remaining {
+		p = p[:m.remaining]
+	}
+	n, err := m.r.Read(p)
+	m.remaining -= int64(n)
+
+	return n, err
+}
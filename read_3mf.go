@@ -0,0 +1,381 @@
+package stl
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// Relationship type of the 3D model part of a 3MF package
+const rel3DModel = "http://schemas.microsoft.com/3dmanufacturing/2013/01/3dmodel"
+
+// Millimeters in each 3MF unit
+var units3MF = map[string]float64{
+	"micron":     0.001,
+	"millimeter": 1,
+	"centimeter": 10,
+	"inch":       25.4,
+	"foot":       304.8,
+	"meter":      1000,
+}
+
+// Deepest nesting of components followed
+const max3MFDepth = 32
+
+// model3MF is the part of a 3MF model used to build Solids
+type model3MF struct {
+	Unit      string `xml:"unit,attr"`
+	Resources struct {
+		BaseMaterials []struct {
+			ID    int `xml:"id,attr"`
+			Bases []struct {
+				Color string `xml:"displaycolor,attr"`
+			} `xml:"base"`
+		} `xml:"basematerials"`
+		ColorGroups []struct {
+			ID     int `xml:"id,attr"`
+			Colors []struct {
+				Color string `xml:"color,attr"`
+			} `xml:"color"`
+		} `xml:"colorgroup"`
+		Objects []object3MF `xml:"object"`
+	} `xml:"resources"`
+	Items []struct {
+		ObjectID  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"build>item"`
+}
+type object3MF struct {
+	ID     int    `xml:"id,attr"`
+	Type   string `xml:"type,attr"`
+	Name   string `xml:"name,attr"`
+	PID    string `xml:"pid,attr"`
+	PIndex string `xml:"pindex,attr"`
+	Mesh   *struct {
+		Vertices []struct {
+			X float32 `xml:"x,attr"`
+			Y float32 `xml:"y,attr"`
+			Z float32 `xml:"z,attr"`
+		} `xml:"vertices>vertex"`
+		Triangles []struct {
+			V1  int    `xml:"v1,attr"`
+			V2  int    `xml:"v2,attr"`
+			V3  int    `xml:"v3,attr"`
+			PID string `xml:"pid,attr"`
+			P1  string `xml:"p1,attr"`
+		} `xml:"triangles>triangle"`
+	} `xml:"mesh"`
+	Components []struct {
+		ObjectID  int    `xml:"objectid,attr"`
+		Transform string `xml:"transform,attr"`
+	} `xml:"components>component"`
+}
+
+// From3MF creates a Solid for each build item of a 3MF package.
+// See stl.From3MFContext for more info
+func From3MF(r io.ReaderAt, size int64) ([]Solid, error) {
+	return From3MFContext(context.Background(), r, size, ReadOptions{})
+}
+
+// From3MFContext creates a Solid for each build item of a 3MF package, named by
+// its object.  Components are flattened, and the transforms of components and
+// build items are applied as in Solid.Transform.  Coordinates are converted to
+// millimeters from the unit of the model.  Normals are taken from the winding.
+// Colors from base materials and color groups are stored in AttrByteCnt using ColorVisCAM.
+// Of opts, MaxBytes limits the uncompressed size of the model, and MaxTriangles is enforced.
+func From3MFContext(ctx context.Context, r io.ReaderAt, size int64, opts ReadOptions) ([]Solid, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: not a zip package: %w", ErrSyntax, err)}
+	}
+
+	name, err := find3DModel(zr)
+	if err != nil {
+		return nil, err
+	}
+
+	var m model3MF
+	if err := read3MFPart(zr, name, opts.MaxBytes, &m); err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	b := builder3MF{model: &m, opts: opts, objects: make(map[int]*object3MF), colors: make(map[int][]Color)}
+	return b.build(ctx)
+}
+
+// From3MFFile creates a Solid for each build item of a 3MF file
+// See stl.From3MF for more info
+func From3MFFile(filename string) ([]Solid, error) {
+	file, err := os.Open(strings.TrimSpace(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, err
+	}
+
+	return From3MF(file, info.Size())
+}
+
+// find3DModel finds the model part from the package relationships
+func find3DModel(zr *zip.Reader) (string, error) {
+	var rels struct {
+		Relationships []struct {
+			Target string `xml:"Target,attr"`
+			Type   string `xml:"Type,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := read3MFPart(zr, "_rels/.rels", 0, &rels); err != nil {
+		return "", err
+	}
+
+	for _, rel := range rels.Relationships {
+		if rel.Type == rel3DModel {
+			return strings.TrimPrefix(path.Clean("/"+rel.Target), "/"), nil
+		}
+	}
+
+	return "", &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: package has no 3D model", ErrSyntax)}
+}
+
+// read3MFPart decodes the XML part of the package with the given name into v
+func read3MFPart(zr *zip.Reader, name string, maxBytes int64, v any) error {
+	var file *zip.File
+	for _, f := range zr.File {
+		if strings.EqualFold(strings.TrimPrefix(f.Name, "/"), name) {
+			file = f
+			break
+		}
+	}
+	if file == nil {
+		return &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: missing part %s", ErrSyntax, name)}
+	}
+
+	rc, err := file.Open()
+	if err != nil {
+		return &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: could not open %s: %w", ErrSyntax, name, err)}
+	}
+	defer rc.Close()
+
+	var r io.Reader = rc
+	if maxBytes > 0 {
+		r = &maxBytesReader{r: rc, remaining: maxBytes}
+	}
+
+	if err := xml.NewDecoder(r).Decode(v); err != nil {
+		if errors.Is(err, ErrTooLarge) {
+			return err
+		}
+		perr := &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: %s: %w", ErrSyntax, name, err)}
+		var serr *xml.SyntaxError
+		if errors.As(err, &serr) {
+			perr.Line = serr.Line
+		}
+		return perr
+	}
+
+	return nil
+}
+
+// builder3MF turns a decoded model into Solids
+type builder3MF struct {
+	model     *model3MF
+	opts      ReadOptions
+	objects   map[int]*object3MF
+	colors    map[int][]Color
+	triangles int
+}
+
+func (b *builder3MF) build(ctx context.Context) ([]Solid, error) {
+	scale := 1.0
+	if b.model.Unit != "" {
+		var ok bool
+		if scale, ok = units3MF[b.model.Unit]; !ok {
+			return nil, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: unknown unit %q", ErrSyntax, b.model.Unit)}
+		}
+	}
+
+	res := &b.model.Resources
+	for i := range res.Objects {
+		b.objects[res.Objects[i].ID] = &res.Objects[i]
+	}
+	for _, g := range res.BaseMaterials {
+		for _, base := range g.Bases {
+			b.colors[g.ID] = append(b.colors[g.ID], parse3MFColor(base.Color))
+		}
+	}
+	for _, g := range res.ColorGroups {
+		for _, c := range g.Colors {
+			b.colors[g.ID] = append(b.colors[g.ID], parse3MFColor(c.Color))
+		}
+	}
+
+	var solids []Solid
+	for _, item := range b.model.Items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		obj, ok := b.objects[item.ObjectID]
+		if !ok {
+			return nil, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: build item refers to missing object %d", ErrSyntax, item.ObjectID)}
+		}
+		if obj.Type != "" && obj.Type != "model" {
+			continue
+		}
+
+		m, err := parse3MFTransform(item.Transform)
+		if err != nil {
+			return nil, err
+		}
+
+		s := Solid{Header: obj.Name}
+		if err := b.addObject(&s, obj, ScaleUniform(scale).Mul(m), 0); err != nil {
+			return nil, err
+		}
+		s.TriangleCount = uint32(len(s.Triangles))
+		solids = append(solids, s)
+	}
+
+	if len(solids) == 0 {
+		return nil, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: no build items", ErrEmpty)}
+	}
+
+	return solids, nil
+}
+
+// addObject adds the Triangles of obj and its components to s, transformed by m
+func (b *builder3MF) addObject(s *Solid, obj *object3MF, m Matrix, depth int) error {
+	if depth > max3MFDepth {
+		return &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: components nested more than %d deep", ErrSyntax, max3MFDepth)}
+	}
+
+	for _, c := range obj.Components {
+		child, ok := b.objects[c.ObjectID]
+		if !ok {
+			return &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: object %d refers to missing object %d", ErrSyntax, obj.ID, c.ObjectID)}
+		}
+		cm, err := parse3MFTransform(c.Transform)
+		if err != nil {
+			return err
+		}
+		if err := b.addObject(s, child, m.Mul(cm), depth+1); err != nil {
+			return err
+		}
+	}
+	if obj.Mesh == nil {
+		return nil
+	}
+
+	mesh := obj.Mesh
+	b.triangles += len(mesh.Triangles)
+	if b.opts.MaxTriangles > 0 && b.triangles > b.opts.MaxTriangles {
+		return &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: limit is %d", ErrTooManyTriangles, b.opts.MaxTriangles)}
+	}
+
+	start := len(s.Triangles)
+	for i, tri := range mesh.Triangles {
+		t := Triangle{}
+		for k, v := range [3]int{tri.V1, tri.V2, tri.V3} {
+			if v < 0 || v >= len(mesh.Vertices) {
+				return &ParseError{Format: Format3MF, Facet: i, Err: fmt.Errorf("%w: object %d vertex index %d out of range, %d defined", ErrSyntax, obj.ID, v, len(mesh.Vertices))}
+			}
+			mv := mesh.Vertices[v]
+			t.Vertices[k] = Coordinate{X: mv.X, Y: mv.Y, Z: mv.Z}
+		}
+		t.Normal = t.ComputeNormal()
+
+		// A triangle's own property wins over its object's
+		pid, index := obj.PID, obj.PIndex
+		if tri.PID != "" {
+			pid = tri.PID
+		}
+		if tri.P1 != "" {
+			index = tri.P1
+		}
+		if c, ok := b.color(pid, index); ok {
+			t.SetColor(ColorVisCAM, c)
+			s.ColorFormat = ColorVisCAM
+		}
+
+		s.Triangles = append(s.Triangles, t)
+	}
+
+	part := Solid{Triangles: s.Triangles[start:]}
+	part.Transform(m)
+
+	return nil
+}
+
+// color looks up the color at index in the property group pid
+func (b *builder3MF) color(pid, index string) (Color, bool) {
+	if pid == "" {
+		return Color{}, false
+	}
+	id, err := strconv.Atoi(pid)
+	if err != nil {
+		return Color{}, false
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil {
+		i = 0
+	}
+
+	group := b.colors[id]
+	if i < 0 || i >= len(group) {
+		return Color{}, false
+	}
+
+	return group[i], true
+}
+
+// parse3MFColor parses a color like #RRGGBB or #RRGGBBAA
+func parse3MFColor(s string) Color {
+	s = strings.TrimPrefix(s, "#")
+	if len(s) == 6 {
+		s += "ff"
+	}
+	v, err := strconv.ParseUint(s, 16, 32)
+	if err != nil || len(s) != 8 {
+		return Color{}
+	}
+
+	return Color{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}
+}
+
+// parse3MFTransform parses the 12 values of a 3MF transform, which are the columns of
+// a 4x3 matrix applied to row vectors
+func parse3MFTransform(s string) (Matrix, error) {
+	if strings.TrimSpace(s) == "" {
+		return Identity(), nil
+	}
+
+	fields := strings.Fields(s)
+	if len(fields) != 12 {
+		return Matrix{}, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: transform needs 12 values, found %d", ErrSyntax, len(fields))}
+	}
+
+	m := Identity()
+	for i, f := range fields {
+		v, err := strconv.ParseFloat(f, 64)
+		if err != nil {
+			return Matrix{}, &ParseError{Format: Format3MF, Facet: -1, Err: fmt.Errorf("%w: could not parse transform value %q", ErrSyntax, f)}
+		}
+		m[i%3][i/3] = v
+	}
+
+	return m, nil
+}
@@ -0,0 +1,149 @@
+package stl
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// package3MF zips up a 3MF package with the given model part
+func package3MF(t *testing.T, model string) *bytes.Reader {
+	t.Helper()
+
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"[Content_Types].xml": contentTypes3MF,
+		"_rels/.rels":         strings.Replace(rels3MF, "/3D/3dmodel.model", "/3D/part.model", 1),
+		"3D/part.model":       model,
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("could not create %s: %v", name, err)
+		}
+		w.Write([]byte(content))
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatalf("could not zip: %v", err)
+	}
+
+	return bytes.NewReader(buf.Bytes())
+}
+
+const triangle3MF = `<mesh>
+ <vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="0" y="1" z="0"/></vertices>
+ <triangles><triangle v1="0" v2="1" v3="2"/></triangles>
+</mesh>`
+
+func TestFrom3MF(t *testing.T) {
+	model := `<?xml version="1.0" encoding="UTF-8"?>
+<model unit="centimeter" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">
+ <resources>
+  <basematerials id="1"><base name="red" displaycolor="#FF0000"/><base name="blue" displaycolor="#0000FFFF"/></basematerials>
+  <object id="2" type="model" name="part" pid="1" pindex="0">
+   <mesh>
+    <vertices><vertex x="0" y="0" z="0"/><vertex x="1" y="0" z="0"/><vertex x="1" y="1" z="0"/><vertex x="0" y="1" z="0"/></vertices>
+    <triangles><triangle v1="0" v2="1" v3="2"/><triangle v1="0" v2="2" v3="3" pid="1" p1="1"/></triangles>
+   </mesh>
+  </object>
+  <object id="3" type="model">` + triangle3MF + `</object>
+  <object id="4" type="model" name="assembly">
+   <components><component objectid="3" transform="1 0 0 0 1 0 0 0 1 0 0 5"/><component objectid="2"/></components>
+  </object>
+  <object id="5" type="support">` + triangle3MF + `</object>
+ </resources>
+ <build>
+  <item objectid="2" transform="1 0 0 0 1 0 0 0 1 10 0 0"/>
+  <item objectid="4" transform="0 1 0 -1 0 0 0 0 1 0 0 0"/>
+  <item objectid="5"/>
+ </build>
+</model>`
+
+	r := package3MF(t, model)
+	solids, err := From3MF(r, r.Size())
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if len(solids) != 2 {
+		t.Fatalf("got %d solids; want 2 without the support", len(solids))
+	}
+
+	// Centimeters are scaled to millimeters after the move
+	part := solids[0]
+	if part.Header != "part" || len(part.Triangles) != 2 || part.TriangleCount != 2 || part.ColorFormat != ColorVisCAM {
+		t.Fatalf("got %q with %d triangles, count %d, %s colors; want part, 2, VisCAM", part.Header, len(part.Triangles), part.TriangleCount, part.ColorFormat)
+	}
+	if got := part.Triangles[0].Vertices[1]; got != (Coordinate{X: 110}) {
+		t.Errorf("got vertex %v; want {110 0 0}", got)
+	}
+	if a := part.SurfaceArea(); !near(a, 100) {
+		t.Errorf("got area %v; want 100", a)
+	}
+	for i, want := range []Color{{R: 255, A: 255}, {B: 255, A: 255}} {
+		if c, ok := part.FacetColor(i); !ok || c != want {
+			t.Errorf("got triangle %d color %v, %t; want %v", i, c, ok, want)
+		}
+	}
+
+	// The triangle moved up, then everything turned a quarter about Z
+	asm := solids[1]
+	if asm.Header != "assembly" || len(asm.Triangles) != 3 {
+		t.Fatalf("got %q with %d triangles; want assembly with 3", asm.Header, len(asm.Triangles))
+	}
+	if got := asm.Triangles[0].Vertices[1]; got != (Coordinate{Y: 10, Z: 50}) {
+		t.Errorf("got vertex %v; want {0 10 50}", got)
+	}
+	if bad := asm.BadNormals(1e-6); len(bad) > 0 {
+		t.Errorf("got bad normals %v", bad)
+	}
+	if _, ok := asm.FacetColor(0); ok {
+		t.Errorf("got a color for an uncolored object")
+	}
+}
+func TestFrom3MF_Errors(t *testing.T) {
+	wrap := func(resources, build string) string {
+		return `<model unit="millimeter"><resources>` + resources + `</resources><build>` + build + `</build></model>`
+	}
+	object := `<object id="1">` + triangle3MF + `</object>`
+
+	for _, tst := range []struct {
+		name  string
+		model string
+		opts  ReadOptions
+		want  error
+	}{
+		{name: "bad xml", model: "<model><resources>", want: ErrSyntax},
+		{name: "no items", model: wrap(object, ""), want: ErrEmpty},
+		{name: "unknown unit", model: strings.Replace(wrap(object, `<item objectid="1"/>`), "millimeter", "furlong", 1), want: ErrSyntax},
+		{name: "missing object", model: wrap(object, `<item objectid="2"/>`), want: ErrSyntax},
+		{name: "bad transform", model: wrap(object, `<item objectid="1" transform="1 0 0"/>`), want: ErrSyntax},
+		{name: "bad index", model: wrap(strings.Replace(object, `v3="2"`, `v3="3"`, 1), `<item objectid="1"/>`), want: ErrSyntax},
+		{name: "cycle", model: wrap(`<object id="1"><components><component objectid="1"/></components></object>`, `<item objectid="1"/>`), want: ErrSyntax},
+		{name: "too many triangles", model: wrap(object, `<item objectid="1"/><item objectid="1"/>`), opts: ReadOptions{MaxTriangles: 1}, want: ErrTooManyTriangles},
+		{name: "too large", model: wrap(object, `<item objectid="1"/>`), opts: ReadOptions{MaxBytes: 100}, want: ErrTooLarge},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			r := package3MF(t, tst.model)
+			_, err := From3MFContext(context.Background(), r, r.Size(), tst.opts)
+			if !errors.Is(err, tst.want) {
+				t.Fatalf("got %v; want %v", err, tst.want)
+			}
+
+			var perr *ParseError
+			if tst.want != ErrTooLarge && (!errors.As(err, &perr) || perr.Format != Format3MF) {
+				t.Errorf("got %v; want a 3MF *ParseError", err)
+			}
+		})
+	}
+
+	// Not a zip at all
+	r := strings.NewReader("solid x\nendsolid x\n")
+	if _, err := From3MF(r, r.Size()); !errors.Is(err, ErrSyntax) {
+		t.Errorf("got %v; want %v", err, ErrSyntax)
+	}
+}
@@ -2,177 +2,351 @@ package stl
 
 import (
 	"bufio"
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"strconv"
 	"strings"
-	"sync"
 )
 
-func fromASCII(br *bufio.Reader) (Solid, error) {
-	header, err := extractASCIIHeader(br)
+func fromASCII(ctx context.Context, br *bufio.Reader, opts ReadOptions) (Solid, error) {
+	solids, err := fromASCIISolids(ctx, br, opts, asciiRules{lenient: opts.Lenient})
 	if err != nil {
 		return Solid{}, err
 	}
 
-	tris, err := extractASCIITriangles(br)
+	return solids[0], nil
+}
+
+// fromASCIISolids reads every solid in the input.
+// Unless rules.multi is set there is only ever one.
+func fromASCIISolids(ctx context.Context, br *bufio.Reader, opts ReadOptions, rules asciiRules) ([]Solid, error) {
+	header, n, err := extractASCIIHeader(br)
 	if err != nil {
-		return Solid{}, err
+		return nil, err
 	}
 
-	return Solid{
-		Header:        header,
-		TriangleCount: uint32(len(tris)),
-		Triangles:     tris,
-	}, nil
-}
-func extractASCIIHeader(br *bufio.Reader) (string, error) {
-	s, err := br.ReadString('\n')
+	src := source{
+		r:      br,
+		split:  splitFacetsASCII(opts.ChunkSize),
+		format: FormatASCII,
+		facets: func(raw []byte) int { return countKeyword(raw, "endfacet") },
+		offset: int64(n),
+		line:   2,
+	}
+	tris, marks, err := extractASCIITriangles(ctx, src, opts, rules)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
-	return strings.TrimSpace(strings.TrimPrefix(string(s), "solid")), nil
-}
+	// Each solid runs from its mark to the next one
+	marks = append([]solidMark{{at: 0, name: header}}, marks...)
+	solids := make([]Solid, len(marks))
+	for i, m := range marks {
+		end := len(tris)
+		if i+1 < len(marks) {
+			end = marks[i+1].at
+		}
+		solids[i] = Solid{
+			Header:        m.name,
+			TriangleCount: uint32(end - m.at),
+			Triangles:     tris[m.at:end:end],
+		}
+	}
 
-// Parsing is done concurrently here depending on concurrencyLevel in stl.go.
-func extractASCIITriangles(br *bufio.Reader) (t []Triangle, err error) {
-	// Read in ASCII data.  Put on work chan raw.
-	raw, errChan := sendASCIIToWorkers(br)
+	return solids, nil
+}
 
-	// Start up workers
-	triParsed := make(chan Triangle)
-	wg := &sync.WaitGroup{}
-	for i := 0; i < concurrencyLevel; i++ {
-		wg.Add(1)
-		go parseTriangles(raw, triParsed, errChan, wg)
+// extractASCIIHeader reads the "solid" line and returns the name and the number of bytes read
+func extractASCIIHeader(br *bufio.Reader) (string, int, error) {
+	s, err := br.ReadString('\n')
+	if err == io.EOF {
+		err = ErrTruncated
+	}
+	if err == nil && !hasSolidPrefix([]byte(s)) {
+		err = fmt.Errorf("%w: missing solid keyword", ErrSyntax)
+	}
+	if err != nil {
+		return "", 0, &ParseError{Format: FormatASCII, Line: 1, Facet: -1, Err: err}
 	}
 
-	// When workers are done, close chans
-	go func() {
-		wg.Wait()
-		close(triParsed)
-		close(errChan)
-	}()
-
-	// Accumulate parsed Triangles until triParsed channel is closed
-	return collectASCIITriangles(triParsed, errChan)
+	// The keyword has already been matched without regard to case
+	return strings.TrimSpace(s[len("solid"):]), len(s), nil
 }
-func sendASCIIToWorkers(br *bufio.Reader) (chan string, chan error) {
-	work := make(chan string)
-	// errChan needs a space to put error and return
-	errChan := make(chan error, concurrencyLevel+1)
 
-	go func() {
-		defer close(work)
+// Parsing is done concurrently here depending on ReadOptions.Workers.
+func extractASCIITriangles(ctx context.Context, src source, opts ReadOptions, rules asciiRules) ([]Triangle, []solidMark, error) {
+	parse := parseASCIIChunk(rules)
+	if opts.RecomputeNormals {
+		parse = recomputingNormals(parse)
+	}
 
-		// Create Scanner with split func for ASCII triangles
-		scanner := bufio.NewScanner(br)
-		scanner.Split(splitTrianglesASCII)
+	// Creating space for 1K triangles as even simple designs have a few hundred
+	return newPipeline(ctx, opts).run(src, parse, 1024)
+}
 
-		// Need to copy each read from the Scanner because it will be overwritten by the next Scan
-		for scanner.Scan() {
-			bin := make([]byte, len(scanner.Text()))
-			copy(bin, scanner.Text())
-			work <- string(bin)
+// parseASCIIChunk returns a parse func for chunks made by splitFacetsASCII
+func parseASCIIChunk(rules asciiRules) func(c chunk) (parsedChunk, error) {
+	return func(c chunk) (parsedChunk, error) {
+		tris, solids, err := trianglesFromASCII(c.raw, c.last, rules)
+		if err != nil {
+			return parsedChunk{}, err.rebase(c.line, c.first, c.offset)
 		}
 
-		if scanner.Err() != nil {
-			errChan <- fmt.Errorf("error reading input: %v", scanner.Err())
-		}
-	}()
+		return parsedChunk{tris: tris, solids: solids}, nil
+	}
+}
 
-	return work, errChan
+// asciiRules are how strictly the facets after the first "solid" line are parsed
+type asciiRules struct {
+	// lenient allows the final "endsolid" to be missing, and ignores any other "solid" and "endsolid" lines
+	lenient bool
+	// multi allows another solid to start after "endsolid"
+	multi bool
 }
-func parseTriangles(raw <-chan string, triParsed chan<- Triangle, errChan chan error, wg *sync.WaitGroup) {
-	defer wg.Done()
 
-	for r := range raw {
-		sl := strings.Split(r, "\n")
+// trianglesFromASCII parses every facet in raw, and where any new solids start.
+// The final chunk must end with "endsolid" unless lenient.
+// The error is located relative to the start of raw.
+func trianglesFromASCII(raw []byte, last bool, rules asciiRules) ([]Triangle, []solidMark, *ParseError) {
+	// A facet is rarely less than 200 bytes
+	tris := make([]Triangle, 0, len(raw)/200+1)
+	var solids []solidMark
+	tk := &asciiTokens{data: raw}
 
-		// Get the normal for a triangle
-		norm, err := extractUnitVector(sl[0])
-		if err != nil {
-			errChan <- err
-			return
+	// ended is set after "endsolid", when only another solid may follow
+	ended := false
+	for {
+		word := tk.next()
+		if word == nil {
+			// Splitting never puts "endsolid" at the end of a chunk other than the last
+			if ended && !last && !rules.multi {
+				return nil, nil, tk.errorAt(len(tris), fmt.Errorf("%w: data after endsolid", ErrSyntax))
+			}
+			if !ended && last && !rules.lenient {
+				tk.mark()
+				return nil, nil, tk.errorAt(len(tris), fmt.Errorf("%w: missing endsolid", ErrTruncated))
+			}
+			return tris, solids, nil
 		}
+		tk.mark()
 
-		// Get coordinates
-		var v [3]Coordinate
-		for i := 0; i < 3; i++ {
-			v[i], err = extractCoordinate(sl[i+2])
+		switch {
+		case ended && rules.multi && isKeyword(word, "solid"):
+			solids = append(solids, solidMark{at: len(tris), name: tk.restOfLine()})
+			ended = false
+		case ended:
+			return nil, nil, tk.errorAt(len(tris), fmt.Errorf("%w: data after endsolid", ErrSyntax))
+		case isKeyword(word, "facet"):
+			tk.markFacet()
+			t, err := extractTriangle(tk)
 			if err != nil {
-				errChan <- err
-				return
+				return nil, nil, tk.errorAt(len(tris), err)
 			}
+			tris = append(tris, t)
+			tk.inFacet = false
+		case isKeyword(word, "endsolid"):
+			tk.restOfLine()
+			ended = !rules.lenient
+		case isKeyword(word, "solid") && rules.lenient:
+			name := tk.restOfLine()
+			if rules.multi {
+				solids = append(solids, solidMark{at: len(tris), name: name})
+			}
+		default:
+			return nil, nil, tk.errorAt(len(tris), fmt.Errorf("%w: got %q; want facet or endsolid", ErrSyntax, word))
 		}
+	}
+}
 
-		triParsed <- Triangle{
-			Normal:   norm,
-			Vertices: v,
+// extractTriangle parses the rest of a facet after the "facet" keyword
+func extractTriangle(tk *asciiTokens) (Triangle, error) {
+	// Get the normal for a triangle
+	norm, err := extractUnitVector(tk)
+	if err != nil {
+		return Triangle{}, err
+	}
+
+	if err := tk.expect("outer", "loop"); err != nil {
+		return Triangle{}, err
+	}
+
+	// Get coordinates
+	var v [3]Coordinate
+	for i := 0; i < 3; i++ {
+		v[i], err = extractCoordinate(tk)
+		if err != nil {
+			return Triangle{}, err
 		}
 	}
+
+	if err := tk.expect("endloop", "endfacet"); err != nil {
+		return Triangle{}, err
+	}
+
+	return Triangle{
+		Normal:   norm,
+		Vertices: v,
+	}, nil
 }
-func extractCoordinate(s string) (Coordinate, error) {
-	sl := strings.Split(strings.TrimSpace(s), " ")
-	if len(sl) != 4 {
-		return Coordinate{}, fmt.Errorf("invalid input for coordinate: %s", strings.TrimSpace(s))
+func extractCoordinate(tk *asciiTokens) (Coordinate, error) {
+	if err := tk.expect("vertex"); err != nil {
+		return Coordinate{}, err
 	}
 
-	x, err := strconv.ParseFloat(sl[1], 32)
+	x, err := tk.float("coordinate x")
 	if err != nil {
-		return Coordinate{}, fmt.Errorf("invalid input for coordinate x: %v", err)
+		return Coordinate{}, err
 	}
-	y, err := strconv.ParseFloat(sl[2], 32)
+	y, err := tk.float("coordinate y")
 	if err != nil {
-		return Coordinate{}, fmt.Errorf("invalid input for coordinate y: %v", err)
+		return Coordinate{}, err
 	}
-	z, err := strconv.ParseFloat(sl[3], 32)
+	z, err := tk.float("coordinate z")
 	if err != nil {
-		return Coordinate{}, fmt.Errorf("invalid input for coordinate z: %v", err)
+		return Coordinate{}, err
 	}
 
 	return Coordinate{
-		X: float32(x),
-		Y: float32(y),
-		Z: float32(z),
+		X: x,
+		Y: y,
+		Z: z,
 	}, nil
 }
-func extractUnitVector(s string) (UnitVector, error) {
-	sl := strings.Split(strings.TrimSpace(s), " ")
-	if len(sl) != 5 {
-		return UnitVector{}, fmt.Errorf("invalid input for unit vector: %s", strings.TrimSpace(s))
+func extractUnitVector(tk *asciiTokens) (UnitVector, error) {
+	if err := tk.expect("normal"); err != nil {
+		return UnitVector{}, err
 	}
 
-	i, err := strconv.ParseFloat(sl[2], 32)
+	i, err := tk.float("unit vector i")
 	if err != nil {
-		return UnitVector{}, fmt.Errorf("invalid input for unit vector i: %v", err)
+		return UnitVector{}, err
 	}
-	j, err := strconv.ParseFloat(sl[3], 32)
+	j, err := tk.float("unit vector j")
 	if err != nil {
-		return UnitVector{}, fmt.Errorf("invalid input for unit vector j: %v", err)
+		return UnitVector{}, err
 	}
-	k, err := strconv.ParseFloat(sl[4], 32)
+	k, err := tk.float("unit vector k")
 	if err != nil {
-		return UnitVector{}, fmt.Errorf("invalid input for unit vector k: %v", err)
+		return UnitVector{}, err
 	}
 
 	return UnitVector{
-		Ni: float32(i),
-		Nj: float32(j),
-		Nk: float32(k),
+		Ni: i,
+		Nj: j,
+		Nk: k,
 	}, nil
 }
-func collectASCIITriangles(triParsed <-chan Triangle, errChan chan error) ([]Triangle, error) {
-	// Creating space for 1K triangles as even simple designs have a few hundred
-	tris := make([]Triangle, 0, 1024)
-	for t := range triParsed {
-		tris = append(tris, t)
+
+// asciiTokens splits ASCII input into words separated by any whitespace, counting lines as it goes.
+// Errors are located at the start of the line where the statement being parsed began,
+// or where the facet began when the input ends part way through it.
+type asciiTokens struct {
+	data []byte
+	pos  int
+	line int
+	// lineStart is where the current line began
+	lineStart int
+	// word and wordLine are the offset of the line the last word is on, and its line number
+	word     int
+	wordLine int
+	// stmt and facet are marked locations for errors, as an offset and line number
+	stmt, stmtLine   int
+	facet, facetLine int
+	inFacet          bool
+}
+
+// next is the next word, or nil at the end of the data
+func (tk *asciiTokens) next() []byte {
+	for tk.pos < len(tk.data) && isSpace(tk.data[tk.pos]) {
+		if tk.data[tk.pos] == '\n' {
+			tk.line++
+			tk.lineStart = tk.pos + 1
+		}
+		tk.pos++
+	}
+
+	start := tk.pos
+	tk.word, tk.wordLine = tk.lineStart, tk.line
+	for tk.pos < len(tk.data) && !isSpace(tk.data[tk.pos]) {
+		tk.pos++
+	}
+	if start == tk.pos {
+		return nil
+	}
+
+	return tk.data[start:tk.pos]
+}
+
+// mark records the last word as the start of a statement
+func (tk *asciiTokens) mark() {
+	tk.stmt, tk.stmtLine = tk.word, tk.wordLine
+}
+
+// markFacet records the last word as the start of a facet, until it is parsed
+func (tk *asciiTokens) markFacet() {
+	tk.facet, tk.facetLine = tk.word, tk.wordLine
+	tk.inFacet = true
+}
+
+// restOfLine moves past the rest of the current line and returns it trimmed, such as the name after "endsolid"
+func (tk *asciiTokens) restOfLine() string {
+	end := len(tk.data)
+	if idx := bytes.IndexByte(tk.data[tk.pos:], '\n'); idx >= 0 {
+		end = tk.pos + idx
+	}
+
+	rest := strings.TrimSpace(string(tk.data[tk.pos:end]))
+	tk.pos = end
+
+	return rest
+}
+
+// expect reads each keyword in order, ignoring case
+func (tk *asciiTokens) expect(keywords ...string) error {
+	for i, kw := range keywords {
+		word := tk.next()
+		if i == 0 {
+			tk.mark()
+		}
+		if word == nil {
+			return fmt.Errorf("%w: missing %s", ErrTruncated, kw)
+		}
+		if !isKeyword(word, kw) {
+			return fmt.Errorf("%w: got %q; want %s", ErrSyntax, word, kw)
+		}
 	}
 
-	err := <-errChan
+	return nil
+}
+
+// float reads the next word as a number.  what describes it for errors.
+func (tk *asciiTokens) float(what string) (float32, error) {
+	word := tk.next()
+	if word == nil {
+		return 0, fmt.Errorf("%w: missing %s", ErrTruncated, what)
+	}
+
+	f, err := strconv.ParseFloat(string(word), 32)
 	if err != nil {
-		return nil, err
+		return 0, fmt.Errorf("%w for %s: %w", ErrSyntax, what, err)
 	}
 
-	return tris, nil
+	return float32(f), nil
+}
+
+// errorAt locates err within the given facet.
+// Truncation is located at the facet that was cut short, and anything else at the current statement.
+func (tk *asciiTokens) errorAt(facet int, err error) *ParseError {
+	offset, line := tk.stmt, tk.stmtLine
+	if tk.inFacet && errors.Is(err, ErrTruncated) {
+		offset, line = tk.facet, tk.facetLine
+	}
+
+	return &ParseError{Format: FormatASCII, Line: line, Facet: facet, Offset: int64(offset), Err: err}
+}
+func isKeyword(word []byte, kw string) bool {
+	return len(word) == len(kw) && bytes.EqualFold(word, []byte(kw))
 }
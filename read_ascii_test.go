@@ -1,6 +1,9 @@
 package stl
 
-import "testing"
+import (
+	"fmt"
+	"testing"
+)
 
 func Test_extractUnitVector(t *testing.T) {
 	for _, tst := range []struct {
@@ -17,7 +20,7 @@ func Test_extractUnitVector(t *testing.T) {
 		},
 	} {
 		t.Run("extractUnitVector", func(t *testing.T) {
-			if got, _ := extractUnitVector(tst.in); got != tst.want {
+			if got, _ := extractUnitVector(afterFacet(tst.in)); got != tst.want {
 				t.Errorf("got %v; want %v", got, tst.want)
 			}
 		})
@@ -30,7 +33,7 @@ func Test_extractUnitVectorError(t *testing.T) {
 	}{
 		{
 			in:   " facet normal 0.01388 -0.69223",
-			want: `invalid input for unit vector: facet normal 0.01388 -0.69223`,
+			want: `input is truncated: missing unit vector k`,
 		},
 		{
 			in:   " facet normal 0.01388 -0.69223 a",
@@ -46,7 +49,7 @@ func Test_extractUnitVectorError(t *testing.T) {
 		},
 	} {
 		t.Run("extractUnitVector", func(t *testing.T) {
-			if _, got := extractUnitVector(tst.in); got == nil || got.Error() != tst.want {
+			if _, got := extractUnitVector(afterFacet(tst.in)); got == nil || got.Error() != tst.want {
 				t.Errorf("got %v; want %v", got, tst.want)
 			}
 		})
@@ -67,7 +70,7 @@ func Test_extractCoordinate(t *testing.T) {
 		},
 	} {
 		t.Run("extractCoordinate", func(t *testing.T) {
-			if got, _ := extractCoordinate(tst.in); got != tst.want {
+			if got, _ := extractCoordinate(&asciiTokens{data: []byte(tst.in)}); got != tst.want {
 				t.Errorf("got %v; want %v", got, tst.want)
 			}
 		})
@@ -80,7 +83,7 @@ func Test_extractCoordinateError(t *testing.T) {
 	}{
 		{
 			in:   "   vertex -1000 0 ",
-			want: `invalid input for coordinate: vertex -1000 0`,
+			want: `input is truncated: missing coordinate z`,
 		},
 		{
 			in:   "   vertex -1000 0 a",
@@ -96,9 +99,203 @@ func Test_extractCoordinateError(t *testing.T) {
 		},
 	} {
 		t.Run("extractCoordinate", func(t *testing.T) {
-			if _, got := extractCoordinate(tst.in); got == nil || got.Error() != tst.want {
+			if _, got := extractCoordinate(&asciiTokens{data: []byte(tst.in)}); got == nil || got.Error() != tst.want {
 				t.Errorf("got %v; want %v", got, tst.want)
 			}
 		})
 	}
 }
+func Test_extractUnitVectorWhitespace(t *testing.T) {
+	want := UnitVector{Ni: 0.01388, Nj: -0.69223, Nk: -0.72154}
+	for _, in := range []string{
+		"facet normal 0.01388 -0.69223 -0.72154",
+		"\tFACET\tNormal  0.01388\t-0.69223   -0.72154\r\n",
+		"facet\r\n\r\nnormal\n0.01388\n-0.69223\n-0.72154",
+	} {
+		in := in
+		t.Run(fmt.Sprintf("%q", in), func(t *testing.T) {
+			t.Parallel()
+			got, err := extractUnitVector(afterFacet(in))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got != want {
+				t.Errorf("got %v; want %v", got, want)
+			}
+		})
+	}
+}
+func Test_trianglesFromASCII(t *testing.T) {
+	want := []Triangle{
+		{
+			Normal:   UnitVector{Ni: 0, Nj: 0, Nk: 1},
+			Vertices: [3]Coordinate{{X: 0, Y: 0, Z: 0}, {X: 1, Y: 0, Z: 0}, {X: 0, Y: 1, Z: 0}},
+		},
+		{
+			Normal:   UnitVector{Ni: 1, Nj: 0, Nk: 0},
+			Vertices: [3]Coordinate{{X: 2, Y: 0, Z: 0}, {X: 2, Y: 1, Z: 0}, {X: 2, Y: 0, Z: 1.5}},
+		},
+	}
+
+	for _, tst := range []struct {
+		name string
+		in   string
+	}{
+		{
+			name: "one keyword per line",
+			in:   " facet normal 0 0 1\n  outer loop\n   vertex 0 0 0\n   vertex 1 0 0\n   vertex 0 1 0\n  endloop\n endfacet\n facet normal 1 0 0\n  outer loop\n   vertex 2 0 0\n   vertex 2 1 0\n   vertex 2 0 1.5\n  endloop\n endfacet\nendsolid test\n",
+		},
+		{
+			name: "CRLF and tabs",
+			in:   "\tfacet normal 0 0 1\r\n\t\touter loop\r\n\t\t\tvertex 0 0 0\r\n\t\t\tvertex 1 0 0\r\n\t\t\tvertex 0 1 0\r\n\t\tendloop\r\n\tendfacet\r\n\tfacet normal 1 0 0\r\n\t\touter loop\r\n\t\t\tvertex 2 0 0\r\n\t\t\tvertex 2 1 0\r\n\t\t\tvertex 2 0 1.5\r\n\t\tendloop\r\n\tendfacet\r\nendsolid test\r\n",
+		},
+		{
+			name: "all on one line, mixed case",
+			in:   "FACET NORMAL 0 0 1 OUTER LOOP VERTEX 0 0 0 VERTEX 1 0 0 VERTEX 0 1 0 ENDLOOP ENDFACET Facet Normal 1 0 0 Outer Loop Vertex 2 0 0 Vertex 2 1 0 Vertex 2 0 1.5 EndLoop EndFacet EndSolid",
+		},
+		{
+			name: "blank lines and extra spaces",
+			in:   "\n\n facet   normal 0   0 1\n\n  outer loop\n   vertex 0 0 0\n   vertex 1   0 0\n   vertex 0 1 0\n  endloop\n endfacet\n\n\n facet normal 1 0 0\n  outer\n  loop\n   vertex 2 0 0\n   vertex 2 1 0\n   vertex 2 0 1.5e0\n  endloop\n endfacet\n\nendsolid\n\n",
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			got, _, err := trianglesFromASCII([]byte(tst.in), true, asciiRules{})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(want) {
+				t.Fatalf("got %d triangles; want %d", len(got), len(want))
+			}
+			for i := range got {
+				if got[i] != want[i] {
+					t.Errorf("got %+v for triangle %d; want %+v", got[i], i, want[i])
+				}
+			}
+		})
+	}
+}
+func Test_trianglesFromASCIIError(t *testing.T) {
+	for _, tst := range []struct {
+		name  string
+		in    string
+		last  bool
+		rules asciiRules
+		want  string
+	}{
+		{
+			name: "missing endsolid",
+			in:   "facet normal 0 0 1 outer loop vertex 0 0 0 vertex 1 0 0 vertex 0 1 0 endloop endfacet\n",
+			last: true,
+			want: "ASCII, line 1, facet 1, byte 86: input is truncated: missing endsolid",
+		},
+		{
+			name: "data after endsolid",
+			in:   "endsolid test\nfacet",
+			last: true,
+			want: "ASCII, line 1, facet 0, byte 14: invalid input: data after endsolid",
+		},
+		{
+			name: "endsolid before the last chunk",
+			in:   "endsolid test\n",
+			last: false,
+			want: "ASCII, facet 0, byte 0: invalid input: data after endsolid",
+		},
+		{
+			name: "unexpected keyword",
+			in:   "facet normal 0 0 1\nouter loop\nvertex 0 0 0\nvertex 1 0 0\nendloop\n",
+			last: true,
+			want: "ASCII, line 4, facet 0, byte 56: invalid input: got \"endloop\"; want vertex",
+		},
+		{
+			name:  "multiple solids, something else after endsolid",
+			in:    "endsolid a\nfacet",
+			last:  true,
+			rules: asciiRules{multi: true},
+			want:  "ASCII, line 1, facet 0, byte 11: invalid input: data after endsolid",
+		},
+		{
+			name:  "multiple solids, missing endsolid",
+			in:    "endsolid a\nsolid b\n",
+			last:  true,
+			rules: asciiRules{multi: true},
+			want:  "ASCII, line 2, facet 0, byte 19: input is truncated: missing endsolid",
+		},
+		{
+			name: "not a facet",
+			in:   "facets",
+			want: "ASCII, facet 0, byte 0: invalid input: got \"facets\"; want facet or endsolid",
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			_, _, err := trianglesFromASCII([]byte(tst.in), tst.last, tst.rules)
+			if err == nil || err.Error() != tst.want {
+				t.Errorf("got %v; want %v", err, tst.want)
+			}
+		})
+	}
+}
+func Test_trianglesFromASCIILenient(t *testing.T) {
+	in := "facet normal 0 0 1 outer loop vertex 0 0 0 vertex 1 0 0 vertex 0 1 0 endloop endfacet\n" +
+		"endsolid a\nsolid b\n" +
+		"facet normal 0 0 1 outer loop vertex 0 0 0 vertex 1 0 0 vertex 0 1 0 endloop endfacet\n"
+
+	got, _, err := trianglesFromASCII([]byte(in), true, asciiRules{lenient: true})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(got) != 2 {
+		t.Errorf("got %d triangles; want 2", len(got))
+	}
+}
+func Test_trianglesFromASCIIMulti(t *testing.T) {
+	facet := "facet normal 0 0 1 outer loop vertex 0 0 0 vertex 1 0 0 vertex 0 1 0 endloop endfacet\n"
+	in := facet + "endsolid a\nsolid  b c \r\nendsolid b c\nSOLID d\n" + facet + facet + "endsolid d\n"
+
+	for _, tst := range []struct {
+		name  string
+		rules asciiRules
+		want  []solidMark
+	}{
+		{
+			name:  "strict",
+			rules: asciiRules{multi: true},
+			want:  []solidMark{{at: 1, name: "b c"}, {at: 1, name: "d"}},
+		},
+		{
+			name:  "lenient",
+			rules: asciiRules{multi: true, lenient: true},
+			want:  []solidMark{{at: 1, name: "b c"}, {at: 1, name: "d"}},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			tris, got, err := trianglesFromASCII([]byte(in), true, tst.rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(tris) != 3 {
+				t.Errorf("got %d triangles; want 3", len(tris))
+			}
+			if len(got) != len(tst.want) {
+				t.Fatalf("got %v; want %v", got, tst.want)
+			}
+			for i := range got {
+				if got[i] != tst.want[i] {
+					t.Errorf("got %v; want %v", got[i], tst.want[i])
+				}
+			}
+		})
+	}
+}
+
+// afterFacet is a tokenizer over in that has already read the "facet" keyword
+func afterFacet(in string) *asciiTokens {
+	tk := &asciiTokens{data: []byte(in)}
+	tk.next()
+	return tk
+}
@@ -2,126 +2,128 @@ package stl
 
 import (
 	"bufio"
+	"context"
 	"encoding/binary"
+	"errors"
 	"fmt"
+	"io"
 	"math"
-	"strings"
-	"sync"
 )
 
-func fromBinary(br *bufio.Reader) (Solid, error) {
-	header, err := extractBinaryHeader(br)
+func fromBinary(ctx context.Context, br *bufio.Reader, opts ReadOptions) (Solid, error) {
+	raw, err := extractBinaryHeader(br)
 	if err != nil {
 		return Solid{}, err
 	}
+	header, color, material := parseBinaryHeader(raw)
 
 	triCount, err := extractBinaryTriangleCount(br)
 	if err != nil {
 		return Solid{}, err
 	}
-	tris, err := extractBinaryTriangles(triCount, br)
+	if opts.MaxTriangles > 0 && int64(triCount) > int64(opts.MaxTriangles) {
+		return Solid{}, fmt.Errorf("%w: header declares %d, limit is %d", ErrTooManyTriangles, triCount, opts.MaxTriangles)
+	}
+
+	// Padding after the declared triangles is never read
+	body := io.Reader(br)
+	if opts.IgnoreTrailing {
+		body = io.LimitReader(br, 50*int64(triCount))
+	}
+	cr := &countingReader{r: body}
+
+	src := source{
+		r:      cr,
+		split:  splitTrianglesBinary(opts.ChunkSize, opts.Lenient),
+		format: FormatBinary,
+		facets: func(raw []byte) int { return len(raw) / 50 },
+		offset: 84,
+	}
+	tris, err := extractBinaryTriangles(ctx, triCount, src, opts)
 	if err != nil {
 		return Solid{}, err
 	}
+	read := uint32(len(tris))
+
+	// Only lenient reads get past a partial triangle
+	if partial := cr.n - 50*int64(read); partial > 0 {
+		opts.warn(&ParseError{Format: FormatBinary, Facet: int(read), Offset: 84 + 50*int64(read), Err: fmt.Errorf("%w: dropped partial triangle of %d bytes", ErrTruncated, partial)})
+	}
+	if opts.IgnoreTrailing {
+		if _, err := br.Peek(1); err == nil {
+			opts.warn(&ParseError{Format: FormatBinary, Facet: -1, Offset: 84 + 50*int64(read), Err: fmt.Errorf("%w: ignored data after %d triangles", ErrCountMismatch, triCount)})
+		}
+	}
+
+	if read != triCount {
+		err := &ParseError{Format: FormatBinary, Facet: -1, Offset: 80, Err: fmt.Errorf("%w: header declares %d triangles, data holds %d", ErrCountMismatch, triCount, read)}
+		if opts.StrictCount && !opts.Lenient {
+			return Solid{}, err
+		}
+		opts.warn(err)
+		if opts.Lenient {
+			triCount = read
+		}
+	}
 
 	return Solid{
 		Header:        header,
 		TriangleCount: triCount,
 		Triangles:     tris,
+		ColorFormat:   detectColorFormat(color, material, tris),
+		Color:         color,
+		Material:      material,
 	}, nil
 }
-func extractBinaryHeader(br *bufio.Reader) (string, error) {
+func extractBinaryHeader(br *bufio.Reader) ([]byte, error) {
 	hBytes := make([]byte, 80)
-	_, err := br.Read(hBytes)
+	_, err := io.ReadFull(br, hBytes)
 	if err != nil {
-		return "", fmt.Errorf("could not read header: %v", err)
+		return nil, binaryReadError(0, fmt.Errorf("could not read header: %w", err))
 	}
 
-	return strings.TrimSpace(string(hBytes)), nil
+	return hBytes, nil
 }
 func extractBinaryTriangleCount(br *bufio.Reader) (uint32, error) {
 	cntBytes := make([]byte, 4)
-	_, err := br.Read(cntBytes)
+	_, err := io.ReadFull(br, cntBytes)
 	if err != nil {
-		return 0, fmt.Errorf("could not read triangle count: %v", err)
+		return 0, binaryReadError(80, fmt.Errorf("could not read triangle count: %w", err))
 	}
 
 	return binary.LittleEndian.Uint32(cntBytes), nil
 }
 
-// Each triangle is 50 bytes.
-// Parsing is done concurrently here depending on concurrencyLevel in stl.go.
-func extractBinaryTriangles(triCount uint32, br *bufio.Reader) ([]Triangle, error) {
-	// Read in binary and send chunks to workers
-	raw, errChan := sendBinaryToWorkers(br)
-
-	// Start up workers
-	triParsed := make(chan []Triangle)
-	workGroup := sync.WaitGroup{}
-	for i := 0; i < concurrencyLevel; i++ {
-		workGroup.Add(1)
-		go parseChunksOfBinary(raw, triParsed, &workGroup)
-	}
-
-	// When workers are done, close chans
-	go func() {
-		workGroup.Wait()
-		close(triParsed)
-		close(errChan)
-	}()
-
-	// Accumulate parsed Triangles until triParsed channel is closed
-	return collectBinaryTriangles(triCount, triParsed, errChan)
-}
-func sendBinaryToWorkers(br *bufio.Reader) (chan []byte, chan error) {
-	raw := make(chan []byte)
-	// errChan needs a space to put error and return
-	errChan := make(chan error, 1)
-
-	go func() {
-		defer close(raw)
-
-		// Create Scanner with split func for binary triangle chunks
-		scanner := bufio.NewScanner(br)
-		scanner.Split(splitTrianglesBinary)
-
-		// Need to copy each read from the Scanner because it will be overwritten by the next Scan
-		for scanner.Scan() {
-			bin := make([]byte, len(scanner.Bytes()))
-			copy(bin, scanner.Bytes())
-			raw <- bin
-		}
-
-		if scanner.Err() != nil {
-			errChan <- fmt.Errorf("error reading input: %v", scanner.Err())
-		}
-	}()
+// binaryReadError locates an error reading the header, treating a short read as ErrTruncated
+func binaryReadError(offset int64, err error) error {
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+		err = fmt.Errorf("%w: %w", ErrTruncated, err)
+	}
 
-	return raw, errChan
+	return &ParseError{Format: FormatBinary, Facet: -1, Offset: offset, Err: err}
 }
-func parseChunksOfBinary(raw <-chan []byte, triParsed chan<- []Triangle, workGroup *sync.WaitGroup) {
-	defer workGroup.Done()
 
-	for r := range raw {
-		t := make([]Triangle, 0, len(r)/50)
-		for i := 0; i < len(r); i += 50 {
-			t = append(t, triangleFromBinary(r[i:i+50]))
-		}
-		triParsed <- t
-	}
-}
-func collectBinaryTriangles(triCount uint32, triParsed <-chan []Triangle, errChan <-chan error) ([]Triangle, error) {
-	tris := make([]Triangle, 0, triCount)
-	for t := range triParsed {
-		tris = append(tris, t...)
+// Each triangle is 50 bytes.
+// Parsing is done concurrently here depending on ReadOptions.Workers.
+func extractBinaryTriangles(ctx context.Context, triCount uint32, src source, opts ReadOptions) ([]Triangle, error) {
+	// The declared count is only a capacity hint, so do not trust a huge one
+	sizeHint := int(min(triCount, 1<<20))
+
+	parse := parseBinaryChunk
+	if opts.RecomputeNormals {
+		parse = recomputingNormals(parse)
 	}
 
-	err := <-errChan
-	if err != nil {
-		return nil, err
+	tris, _, err := newPipeline(ctx, opts).run(src, parse, sizeHint)
+	return tris, err
+}
+func parseBinaryChunk(c chunk) (parsedChunk, error) {
+	t := make([]Triangle, 0, len(c.raw)/50)
+	for i := 0; i < len(c.raw); i += 50 {
+		t = append(t, triangleFromBinary(c.raw[i:i+50]))
 	}
 
-	return tris, nil
+	return parsedChunk{tris: t}, nil
 }
 func triangleFromBinary(bin []byte) Triangle {
 	return Triangle{
@@ -131,7 +133,7 @@ func triangleFromBinary(bin []byte) Triangle {
 			coordinateFromBinary(bin[24:36]),
 			coordinateFromBinary(bin[36:48]),
 		},
-		AttrByteCnt: uint16(bin[48])<<8 | uint16(bin[49]),
+		AttrByteCnt: binary.LittleEndian.Uint16(bin[48:50]),
 	}
 }
 func coordinateFromBinary(bin []byte) Coordinate {
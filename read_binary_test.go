@@ -79,3 +79,12 @@ func Test_triangleFromBinary(t *testing.T) {
 		t.Errorf("got %d for attrByteCnt; want %d", got.AttrByteCnt, want.AttrByteCnt)
 	}
 }
+func Test_triangleFromBinaryAttr(t *testing.T) {
+	// Attribute byte count is little-endian like the rest of the format
+	bin := make([]byte, 50)
+	bin[48], bin[49] = 0x1f, 0x80
+
+	if got := triangleFromBinary(bin).AttrByteCnt; got != 0x801f {
+		t.Errorf("got %#x; want %#x", got, 0x801f)
+	}
+}
@@ -0,0 +1,277 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Longest OBJ line accepted, after joining continued lines
+const maxOBJLine = 1 << 24
+
+// FromOBJ creates a Solid for each object or group of Wavefront OBJ input.
+// See stl.FromOBJContext for more info
+func FromOBJ(r io.Reader) ([]Solid, error) {
+	return FromOBJContext(context.Background(), r, ReadOptions{})
+}
+
+// FromOBJContext creates a Solid for each object or group of Wavefront OBJ input,
+// with its name from the "o" or "g" statement as the Header.  Faces before any
+// name are in a Solid with an empty Header, and a name seen again adds to its Solid.
+// Faces with more than three vertices are split into a fan of Triangles from the
+// first vertex.  Normals are taken from the winding, falling back to the mean of
+// any vertex normals for a Triangle with no area.  Statements other than v, vn, f,
+// o and g are ignored.
+// Of opts, MaxBytes and MaxTriangles are enforced, and Lenient drops faces that
+// refer to missing vertices, reporting each to OnWarning, instead of returning an error.
+func FromOBJContext(ctx context.Context, r io.Reader, opts ReadOptions) ([]Solid, error) {
+	if opts.MaxBytes > 0 {
+		if size := inputSize(r); size > opts.MaxBytes {
+			return nil, fmt.Errorf("%w: %d bytes, limit is %d", ErrTooLarge, size, opts.MaxBytes)
+		}
+		r = &maxBytesReader{r: r, remaining: opts.MaxBytes}
+	}
+
+	p := objParser{opts: opts, named: make(map[string]int)}
+	if err := p.parse(ctx, r); err != nil {
+		return nil, err
+	}
+
+	solids := make([]Solid, 0, len(p.solids))
+	for _, s := range p.solids {
+		if len(s.Triangles) > 0 {
+			s.TriangleCount = uint32(len(s.Triangles))
+			solids = append(solids, s)
+		}
+	}
+	if len(solids) == 0 {
+		return nil, &ParseError{Format: FormatOBJ, Facet: -1, Err: fmt.Errorf("%w: no faces", ErrEmpty)}
+	}
+
+	return solids, nil
+}
+
+// FromOBJFile creates a Solid for each object or group of an OBJ file
+// See stl.FromOBJ for more info
+func FromOBJFile(filename string) ([]Solid, error) {
+	file, err := os.Open(strings.TrimSpace(filename))
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return FromOBJ(file)
+}
+
+// objParser holds the state of OBJ input read so far
+type objParser struct {
+	opts      ReadOptions
+	vertices  []Coordinate
+	normals   []UnitVector
+	solids    []Solid
+	named     map[string]int
+	current   int
+	triangles int
+
+	// Where the statement being parsed starts
+	line   int
+	offset int64
+}
+
+func (p *objParser) parse(ctx context.Context, r io.Reader) error {
+	// Keep track of where each line starts
+	var consumed int64
+	start := int64(0)
+	sc := bufio.NewScanner(r)
+	sc.Buffer(nil, maxOBJLine)
+	sc.Split(func(data []byte, atEOF bool) (int, []byte, error) {
+		adv, tok, err := bufio.ScanLines(data, atEOF)
+		if tok != nil {
+			start = consumed
+		}
+		consumed += int64(adv)
+		return adv, tok, err
+	})
+
+	p.solids = []Solid{{}}
+	var stmt []byte
+	for n := 1; sc.Scan(); n++ {
+		if n%4096 == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+
+		line := sc.Bytes()
+		if len(stmt) == 0 {
+			p.line, p.offset = n, start
+		}
+
+		// A backslash at the end joins the next line on
+		if bytes.HasSuffix(line, []byte{'\\'}) {
+			stmt = append(stmt, line[:len(line)-1]...)
+			stmt = append(stmt, ' ')
+			continue
+		}
+		if len(stmt) > 0 {
+			line = append(stmt, line...)
+			stmt = stmt[:0]
+		}
+
+		if err := p.statement(string(line)); err != nil {
+			return err
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return fmt.Errorf("error reading input: %w", err)
+	}
+
+	return nil
+}
+
+// statement parses a single line
+func (p *objParser) statement(line string) error {
+	if i := strings.IndexByte(line, '#'); i >= 0 {
+		line = line[:i]
+	}
+	fields := strings.Fields(line)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	switch fields[0] {
+	case "v":
+		v, err := p.floats(fields, 3)
+		if err != nil {
+			return err
+		}
+		p.vertices = append(p.vertices, Coordinate{X: v[0], Y: v[1], Z: v[2]})
+	case "vn":
+		v, err := p.floats(fields, 3)
+		if err != nil {
+			return err
+		}
+		p.normals = append(p.normals, UnitVector{Ni: v[0], Nj: v[1], Nk: v[2]})
+	case "f":
+		return p.face(fields[1:])
+	case "o", "g":
+		p.use(strings.Join(fields[1:], " "))
+	}
+
+	return nil
+}
+
+// use makes the named Solid the one faces are added to
+func (p *objParser) use(name string) {
+	if i, ok := p.named[name]; ok {
+		p.current = i
+		return
+	}
+
+	// An unnamed Solid with nothing in it yet takes the name
+	if cur := &p.solids[p.current]; len(cur.Triangles) == 0 && cur.Header == "" && p.current == len(p.solids)-1 {
+		cur.Header = name
+	} else {
+		p.current = len(p.solids)
+		p.solids = append(p.solids, Solid{Header: name})
+	}
+	p.named[name] = p.current
+}
+
+// floats parses the first n values after the keyword
+func (p *objParser) floats(fields []string, n int) ([]float32, error) {
+	if len(fields) < n+1 {
+		return nil, p.errorf("%w: %s needs %d values, found %d", ErrSyntax, fields[0], n, len(fields)-1)
+	}
+
+	v := make([]float32, n)
+	for i := range v {
+		f, err := strconv.ParseFloat(fields[i+1], 32)
+		if err != nil {
+			return nil, p.errorf("%w: could not parse %s value %q", ErrSyntax, fields[0], fields[i+1])
+		}
+		v[i] = float32(f)
+	}
+
+	return v, nil
+}
+
+// face adds a fan of Triangles for a face with vertex references like v, v/vt, v/vt/vn or v//vn
+func (p *objParser) face(refs []string) error {
+	if len(refs) < 3 {
+		return p.errorf("%w: face needs 3 vertices, found %d", ErrSyntax, len(refs))
+	}
+
+	verts := make([]Coordinate, len(refs))
+	var normal Vec3
+	for i, ref := range refs {
+		parts := strings.Split(ref, "/")
+
+		v, err := objIndex(parts[0], len(p.vertices))
+		if err != nil {
+			return p.dropFace(fmt.Errorf("vertex %w", err))
+		}
+		verts[i] = p.vertices[v]
+
+		if len(parts) > 2 && parts[2] != "" {
+			n, err := objIndex(parts[2], len(p.normals))
+			if err != nil {
+				return p.dropFace(fmt.Errorf("normal %w", err))
+			}
+			normal = normal.Add(p.normals[n].Vec3())
+		}
+	}
+
+	if p.opts.MaxTriangles > 0 && p.triangles+len(verts)-2 > p.opts.MaxTriangles {
+		return p.errorf("%w: limit is %d", ErrTooManyTriangles, p.opts.MaxTriangles)
+	}
+	p.triangles += len(verts) - 2
+
+	cur := &p.solids[p.current]
+	for i := 1; i+1 < len(verts); i++ {
+		t := Triangle{Vertices: [3]Coordinate{verts[0], verts[i], verts[i+1]}}
+		t.Normal = t.ComputeNormal()
+		if t.Normal == (UnitVector{}) {
+			t.Normal = normal.Normalize().UnitVector()
+		}
+		cur.Triangles = append(cur.Triangles, t)
+	}
+
+	return nil
+}
+
+// dropFace returns err, or only reports it when Lenient
+func (p *objParser) dropFace(err error) error {
+	perr := p.errorf("%w: %w", ErrSyntax, err)
+	if !p.opts.Lenient {
+		return perr
+	}
+	p.opts.warn(perr)
+
+	return nil
+}
+func (p *objParser) errorf(format string, a ...any) *ParseError {
+	return &ParseError{Format: FormatOBJ, Line: p.line, Facet: -1, Offset: p.offset, Err: fmt.Errorf(format, a...)}
+}
+
+// objIndex converts a 1-based index, or negative index counting back from the end, to 0-based
+func objIndex(s string, count int) (int, error) {
+	i, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("index %q is not a number", s)
+	}
+
+	switch {
+	case i > 0 && i <= count:
+		return i - 1, nil
+	case i < 0 && -i <= count:
+		return count + i, nil
+	default:
+		return 0, fmt.Errorf("index %d out of range, %d defined", i, count)
+	}
+}
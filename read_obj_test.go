@@ -0,0 +1,228 @@
+package stl
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestFromOBJ(t *testing.T) {
+	quad := `# A unit square split in two
+v 0 0 0
+v 1 0 0
+v 1 1 0
+v 0 1 0
+vn 0 0 1
+f 1//1 2//1 3//1 4//1
+`
+	for _, tst := range []struct {
+		name       string
+		in         string
+		wantNames  []string
+		wantCounts []int
+	}{
+		{
+			name:       "quad",
+			in:         quad,
+			wantNames:  []string{""},
+			wantCounts: []int{2},
+		},
+		{
+			name: "references",
+			in: `v 0 0 0
+v 1 0 0
+v 0 1 0
+vt 0 0
+vn 0 0 1
+f 1 2 3
+f 1/1 2/1 3/1
+f 1/1/1 2/1/1 3/1/1
+f -3 -2 -1
+`,
+			wantNames:  []string{""},
+			wantCounts: []int{4},
+		},
+		{
+			name: "objects and groups",
+			in: `o first
+v 0 0 0
+v 1 0 0
+v 0 1 0
+f 1 2 3
+g second part
+f 3 2 1
+g first
+f 1 3 2
+o empty
+`,
+			wantNames:  []string{"first", "second part"},
+			wantCounts: []int{2, 1},
+		},
+		{
+			name: "faces before a name",
+			in: `v 0 0 0
+v 1 0 0
+v 0 1 0
+f 1 2 3
+o named
+f 1 3 2
+`,
+			wantNames:  []string{"", "named"},
+			wantCounts: []int{1, 1},
+		},
+		{
+			name:       "continued lines and comments",
+			in:         "v 0 0 0 # origin\r\nv 1 0 0\r\nv 0 1 0\r\nf 1 \\\r\n 2 3\r\n",
+			wantNames:  []string{""},
+			wantCounts: []int{1},
+		},
+		{
+			name: "pentagon",
+			in: `v 0 0 0
+v 2 0 0
+v 3 1 0
+v 1 3 0
+v -1 1 0
+f 1 2 3 4 5
+`,
+			wantNames:  []string{""},
+			wantCounts: []int{3},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			solids, err := FromOBJ(strings.NewReader(tst.in))
+			if err != nil {
+				t.Fatalf("could not read: %v", err)
+			}
+			if len(solids) != len(tst.wantNames) {
+				t.Fatalf("got %d solids; want %d", len(solids), len(tst.wantNames))
+			}
+			for i, s := range solids {
+				if s.Header != tst.wantNames[i] || len(s.Triangles) != tst.wantCounts[i] || s.TriangleCount != uint32(tst.wantCounts[i]) {
+					t.Errorf("got solid %d %q with %d triangles, count %d; want %q, %d", i, s.Header, len(s.Triangles), s.TriangleCount, tst.wantNames[i], tst.wantCounts[i])
+				}
+				if bad := s.BadNormals(1e-6); len(bad) > 0 {
+					t.Errorf("got bad normals %v", bad)
+				}
+			}
+		})
+	}
+
+	solids, err := FromOBJ(strings.NewReader(quad))
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if a := solids[0].SurfaceArea(); !near(a, 1) {
+		t.Errorf("got area %v; want 1", a)
+	}
+	if got := solids[0].Triangles[1].Vertices; got != [3]Coordinate{{}, {X: 1, Y: 1}, {Y: 1}} {
+		t.Errorf("got second triangle %v; want a fan from the first vertex", got)
+	}
+}
+func TestFromOBJ_Errors(t *testing.T) {
+	for _, tst := range []struct {
+		name       string
+		in         string
+		opts       ReadOptions
+		want       error
+		wantLine   int
+		wantOffset int64
+	}{
+		{
+			name:       "bad vertex",
+			in:         "v 0 0 0\nv 1 x 0\n",
+			want:       ErrSyntax,
+			wantLine:   2,
+			wantOffset: 8,
+		},
+		{
+			name:       "short vertex",
+			in:         "v 0 0\n",
+			want:       ErrSyntax,
+			wantLine:   1,
+			wantOffset: 0,
+		},
+		{
+			name:       "missing vertex",
+			in:         "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 4\n",
+			want:       ErrSyntax,
+			wantLine:   4,
+			wantOffset: 24,
+		},
+		{
+			name:       "zero index",
+			in:         "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 0 1 2\n",
+			want:       ErrSyntax,
+			wantLine:   4,
+			wantOffset: 24,
+		},
+		{
+			name:       "missing normal",
+			in:         "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1//1 2//1 3//1\n",
+			want:       ErrSyntax,
+			wantLine:   4,
+			wantOffset: 24,
+		},
+		{
+			name:       "two vertices",
+			in:         "v 0 0 0\nv 1 0 0\nf 1 2\n",
+			want:       ErrSyntax,
+			wantLine:   3,
+			wantOffset: 16,
+		},
+		{
+			name:     "no faces",
+			in:       "v 0 0 0\n",
+			want:     ErrEmpty,
+			wantLine: 0,
+		},
+		{
+			name:       "too many triangles",
+			in:         "v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\nf 1 3 2\n",
+			opts:       ReadOptions{MaxTriangles: 1},
+			want:       ErrTooManyTriangles,
+			wantLine:   5,
+			wantOffset: 32,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := FromOBJContext(context.Background(), strings.NewReader(tst.in), tst.opts)
+			if !errors.Is(err, tst.want) {
+				t.Fatalf("got %v; want %v", err, tst.want)
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %T; want *ParseError", err)
+			}
+			if perr.Format != FormatOBJ || perr.Line != tst.wantLine || perr.Offset != tst.wantOffset {
+				t.Errorf("got %s line %d byte %d; want OBJ line %d byte %d", perr.Format, perr.Line, perr.Offset, tst.wantLine, tst.wantOffset)
+			}
+		})
+	}
+
+	if _, err := FromOBJContext(context.Background(), strings.NewReader("v 0 0 0\n"), ReadOptions{MaxBytes: 4}); !errors.Is(err, ErrTooLarge) {
+		t.Errorf("got %v; want %v", err, ErrTooLarge)
+	}
+}
+func TestFromOBJ_Lenient(t *testing.T) {
+	var warnings []error
+	solids, err := FromOBJContext(context.Background(), strings.NewReader("v 0 0 0\nv 1 0 0\nv 0 1 0\nf 1 2 3\nf 1 2 9\n"), ReadOptions{
+		Lenient:   true,
+		OnWarning: func(err error) { warnings = append(warnings, err) },
+	})
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if len(solids) != 1 || len(solids[0].Triangles) != 1 {
+		t.Errorf("got %d solids; want 1 with 1 triangle", len(solids))
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], ErrSyntax) {
+		t.Errorf("got warnings %v; want 1 syntax error", warnings)
+	}
+}
@@ -0,0 +1,517 @@
+package stl
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PLYEncoding is the way values are stored in the body of a PLY file
+type PLYEncoding int
+
+// PLY encodings
+const (
+	PLYASCII PLYEncoding = iota
+	PLYBinaryLittleEndian
+	PLYBinaryBigEndian
+)
+
+// String is the name of the PLYEncoding used in the "format" line of a PLY header
+func (e PLYEncoding) String() string {
+	switch e {
+	case PLYASCII:
+		return "ascii"
+	case PLYBinaryLittleEndian:
+		return "binary_little_endian"
+	case PLYBinaryBigEndian:
+		return "binary_big_endian"
+	default:
+		return "unknown"
+	}
+}
+
+// plyType is the type of a PLY property value
+type plyType struct {
+	size     int
+	float    bool
+	signed   bool
+	maxColor float64
+}
+
+var plyTypes = map[string]plyType{
+	"char":    {size: 1, signed: true, maxColor: 127},
+	"int8":    {size: 1, signed: true, maxColor: 127},
+	"uchar":   {size: 1, maxColor: 255},
+	"uint8":   {size: 1, maxColor: 255},
+	"short":   {size: 2, signed: true, maxColor: 32767},
+	"int16":   {size: 2, signed: true, maxColor: 32767},
+	"ushort":  {size: 2, maxColor: 65535},
+	"uint16":  {size: 2, maxColor: 65535},
+	"int":     {size: 4, signed: true, maxColor: 2147483647},
+	"int32":   {size: 4, signed: true, maxColor: 2147483647},
+	"uint":    {size: 4, maxColor: 4294967295},
+	"uint32":  {size: 4, maxColor: 4294967295},
+	"float":   {size: 4, float: true, maxColor: 1},
+	"float32": {size: 4, float: true, maxColor: 1},
+	"double":  {size: 8, float: true, maxColor: 1},
+	"float64": {size: 8, float: true, maxColor: 1},
+}
+
+// plyProperty is a property of a PLY element.  List properties have a count type.
+type plyProperty struct {
+	name  string
+	typ   plyType
+	count *plyType
+}
+
+// plyElement is a kind of PLY element, and how many there are
+type plyElement struct {
+	name  string
+	count int64
+	props []plyProperty
+}
+
+// FromPLY creates a Solid from PLY input in any of its encodings.
+// See stl.FromPLYContext for more info
+func FromPLY(r io.Reader) (Solid, error) {
+	return FromPLYContext(context.Background(), r, ReadOptions{})
+}
+
+// FromPLYContext creates a Solid from the vertex and face elements of PLY input,
+// in ASCII or binary of either byte order.  Other elements and properties are read
+// past.  Faces with more than three vertices are split into a fan of Triangles from
+// the first vertex, and normals are taken from the winding.  The first comment in
+// the header is used as the Header.  Input without faces is an ErrEmpty.
+// Colors are stored in AttrByteCnt using ColorVisCAM: a face's own red, green and
+// blue when it has them, or else the mean of the colors of its vertices.
+// Of opts, MaxBytes and MaxTriangles are enforced.
+func FromPLYContext(ctx context.Context, r io.Reader, opts ReadOptions) (Solid, error) {
+	if opts.MaxBytes > 0 {
+		if size := inputSize(r); size > opts.MaxBytes {
+			return Solid{}, fmt.Errorf("%w: %d bytes, limit is %d", ErrTooLarge, size, opts.MaxBytes)
+		}
+		r = &maxBytesReader{r: r, remaining: opts.MaxBytes}
+	}
+
+	cr := &countingReader{r: r}
+	p := plyParser{br: bufio.NewReaderSize(cr, defaultBufferSize), counter: cr, opts: opts}
+	if err := p.readHeader(); err != nil {
+		return Solid{}, err
+	}
+	if err := p.readBody(ctx); err != nil {
+		return Solid{}, err
+	}
+
+	return p.solid()
+}
+
+// FromPLYFile creates a Solid from a PLY file
+// See stl.FromPLY for more info
+func FromPLYFile(filename string) (Solid, error) {
+	file, err := os.Open(strings.TrimSpace(filename))
+	if err != nil {
+		return Solid{}, err
+	}
+	defer file.Close()
+
+	return FromPLY(file)
+}
+
+// plyParser holds the state of PLY input read so far
+type plyParser struct {
+	br       *bufio.Reader
+	counter  *countingReader
+	opts     ReadOptions
+	encoding PLYEncoding
+	order    binary.ByteOrder
+	elements []plyElement
+	header   string
+	line     int
+	body     bool
+
+	vertices []Coordinate
+	colors   []Color
+	normals  []Vec3
+	faces    []plyFace
+}
+
+// plyFace is a face read from the input, resolved once every vertex is known
+type plyFace struct {
+	verts    []int
+	color    Color
+	hasColor bool
+}
+
+// offset is how far into the input the parser is
+func (p *plyParser) offset() int64 {
+	return p.counter.n - int64(p.br.Buffered())
+}
+func (p *plyParser) errorf(facet int, format string, a ...any) *ParseError {
+	// Binary bodies have no lines
+	line := p.line
+	if p.body && p.encoding != PLYASCII {
+		line = 0
+	}
+
+	return &ParseError{Format: FormatPLY, Line: line, Facet: facet, Offset: p.offset(), Err: fmt.Errorf(format, a...)}
+}
+
+// readLine reads the next line without its line ending, or returns io.EOF
+func (p *plyParser) readLine() (string, error) {
+	line, err := p.br.ReadString('\n')
+	if err == io.EOF && line != "" {
+		err = nil
+	}
+	if err == io.EOF {
+		return "", err
+	}
+	if err != nil {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	p.line++
+
+	return strings.TrimRight(line, "\r\n"), nil
+}
+func (p *plyParser) readHeader() error {
+	magic, err := p.readLine()
+	if err == io.EOF {
+		return p.errorf(-1, "%w", ErrEmpty)
+	}
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(magic) != "ply" {
+		return p.errorf(-1, "%w: does not start with \"ply\"", ErrSyntax)
+	}
+
+	format := false
+	for {
+		line, err := p.readLine()
+		if err == io.EOF {
+			return p.errorf(-1, "%w: missing end_header", ErrTruncated)
+		}
+		if err != nil {
+			return err
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+		switch fields[0] {
+		case "format":
+			if len(fields) < 2 {
+				return p.errorf(-1, "%w: format needs an encoding", ErrSyntax)
+			}
+			switch fields[1] {
+			case "ascii":
+				p.encoding = PLYASCII
+			case "binary_little_endian":
+				p.encoding, p.order = PLYBinaryLittleEndian, binary.LittleEndian
+			case "binary_big_endian":
+				p.encoding, p.order = PLYBinaryBigEndian, binary.BigEndian
+			default:
+				return p.errorf(-1, "%w: unknown format %q", ErrSyntax, fields[1])
+			}
+			format = true
+		case "comment":
+			if p.header == "" {
+				p.header = strings.TrimSpace(strings.TrimPrefix(strings.TrimSpace(line), "comment"))
+			}
+		case "element":
+			if len(fields) != 3 {
+				return p.errorf(-1, "%w: element needs a name and count", ErrSyntax)
+			}
+			count, err := strconv.ParseInt(fields[2], 10, 64)
+			if err != nil || count < 0 {
+				return p.errorf(-1, "%w: bad count %q for element %s", ErrSyntax, fields[2], fields[1])
+			}
+			p.elements = append(p.elements, plyElement{name: fields[1], count: count})
+		case "property":
+			if err := p.readProperty(fields); err != nil {
+				return err
+			}
+		case "end_header":
+			if !format {
+				return p.errorf(-1, "%w: missing format", ErrSyntax)
+			}
+			p.body = true
+			return nil
+		}
+	}
+}
+func (p *plyParser) readProperty(fields []string) error {
+	if len(p.elements) == 0 {
+		return p.errorf(-1, "%w: property before any element", ErrSyntax)
+	}
+	e := &p.elements[len(p.elements)-1]
+
+	if len(fields) == 5 && fields[1] == "list" {
+		count, ok := plyTypes[fields[2]]
+		typ, ok2 := plyTypes[fields[3]]
+		if !ok || !ok2 || count.float {
+			return p.errorf(-1, "%w: bad list types %s %s", ErrSyntax, fields[2], fields[3])
+		}
+		e.props = append(e.props, plyProperty{name: fields[4], typ: typ, count: &count})
+		return nil
+	}
+
+	if len(fields) != 3 {
+		return p.errorf(-1, "%w: property needs a type and name", ErrSyntax)
+	}
+	typ, ok := plyTypes[fields[1]]
+	if !ok {
+		return p.errorf(-1, "%w: unknown type %q", ErrSyntax, fields[1])
+	}
+	e.props = append(e.props, plyProperty{name: fields[2], typ: typ})
+
+	return nil
+}
+
+// readBody reads every element, keeping vertices and faces
+func (p *plyParser) readBody(ctx context.Context) error {
+	var buf [8]byte
+	var fields []string
+	triangles := 0
+
+	for _, e := range p.elements {
+		// Find the properties to keep
+		idx := func(names ...string) int {
+			for _, n := range names {
+				for i, prop := range e.props {
+					if prop.name == n && prop.count == nil {
+						return i
+					}
+				}
+			}
+			return -1
+		}
+		x, y, z := idx("x"), idx("y"), idx("z")
+		nx, ny, nz := idx("nx"), idx("ny"), idx("nz")
+		red, green, blue := idx("red", "diffuse_red"), idx("green", "diffuse_green"), idx("blue", "diffuse_blue")
+		hasColor := red >= 0 && green >= 0 && blue >= 0
+		list := -1
+		for i, prop := range e.props {
+			if prop.count != nil && (prop.name == "vertex_indices" || prop.name == "vertex_index") {
+				list = i
+			}
+		}
+
+		vals := make([]float64, len(e.props))
+		var items []float64
+		for n := int64(0); n < e.count; n++ {
+			if n%4096 == 0 {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+			}
+			facet := -1
+			if e.name == "face" {
+				facet = int(n)
+			}
+
+			if p.encoding == PLYASCII {
+				line, err := p.readLine()
+				if err == io.EOF {
+					return p.errorf(facet, "%w: %d of %d %s elements read", ErrTruncated, n, e.count, e.name)
+				}
+				if err != nil {
+					return err
+				}
+				fields = strings.Fields(line)
+			}
+
+			// Read each property, in order
+			items = items[:0]
+			for i, prop := range e.props {
+				count := 1
+				if prop.count != nil {
+					c, err := p.value(*prop.count, &fields, buf[:], facet)
+					if err != nil {
+						return err
+					}
+					if c < 0 || c != math.Trunc(c) || c > 1<<16 {
+						return p.errorf(facet, "%w: bad list length %v", ErrSyntax, c)
+					}
+					count = int(c)
+				}
+				for k := 0; k < count; k++ {
+					v, err := p.value(prop.typ, &fields, buf[:], facet)
+					if err != nil {
+						return err
+					}
+					if i == list {
+						items = append(items, v)
+					}
+					if k == 0 {
+						vals[i] = v
+					}
+				}
+			}
+			if p.encoding == PLYASCII && len(fields) > 0 {
+				return p.errorf(facet, "%w: %d values left over in %s element", ErrSyntax, len(fields), e.name)
+			}
+
+			switch e.name {
+			case "vertex":
+				c := Coordinate{}
+				if x >= 0 && y >= 0 && z >= 0 {
+					c = Coordinate{X: float32(vals[x]), Y: float32(vals[y]), Z: float32(vals[z])}
+				}
+				p.vertices = append(p.vertices, c)
+				if hasColor {
+					p.colors = append(p.colors, Color{
+						R: plyColor(vals[red], e.props[red].typ),
+						G: plyColor(vals[green], e.props[green].typ),
+						B: plyColor(vals[blue], e.props[blue].typ),
+						A: 255,
+					})
+				}
+				if nx >= 0 && ny >= 0 && nz >= 0 {
+					p.normals = append(p.normals, Vec3{X: vals[nx], Y: vals[ny], Z: vals[nz]})
+				}
+			case "face":
+				if list < 0 {
+					continue
+				}
+				if len(items) < 3 {
+					return p.errorf(facet, "%w: face needs 3 vertices, found %d", ErrSyntax, len(items))
+				}
+				triangles += len(items) - 2
+				if p.opts.MaxTriangles > 0 && triangles > p.opts.MaxTriangles {
+					return p.errorf(facet, "%w: limit is %d", ErrTooManyTriangles, p.opts.MaxTriangles)
+				}
+
+				f := plyFace{verts: make([]int, len(items)), hasColor: hasColor}
+				for i, v := range items {
+					f.verts[i] = int(v)
+				}
+				if hasColor {
+					f.color = Color{
+						R: plyColor(vals[red], e.props[red].typ),
+						G: plyColor(vals[green], e.props[green].typ),
+						B: plyColor(vals[blue], e.props[blue].typ),
+						A: 255,
+					}
+				}
+				p.faces = append(p.faces, f)
+			}
+		}
+	}
+
+	return nil
+}
+
+// value reads a single value, from fields for ASCII or the input for binary
+func (p *plyParser) value(typ plyType, fields *[]string, buf []byte, facet int) (float64, error) {
+	if p.encoding == PLYASCII {
+		if len(*fields) == 0 {
+			return 0, p.errorf(facet, "%w: missing value", ErrSyntax)
+		}
+		s := (*fields)[0]
+		*fields = (*fields)[1:]
+
+		v, err := strconv.ParseFloat(s, 64)
+		if err != nil {
+			return 0, p.errorf(facet, "%w: could not parse value %q", ErrSyntax, s)
+		}
+		return v, nil
+	}
+
+	b := buf[:typ.size]
+	if _, err := io.ReadFull(p.br, b); err != nil {
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return 0, p.errorf(facet, "%w: input ended in an element", ErrTruncated)
+		}
+		return 0, fmt.Errorf("error reading input: %w", err)
+	}
+
+	switch {
+	case typ.float && typ.size == 4:
+		return float64(math.Float32frombits(p.order.Uint32(b))), nil
+	case typ.float:
+		return math.Float64frombits(p.order.Uint64(b)), nil
+	case typ.size == 1 && typ.signed:
+		return float64(int8(b[0])), nil
+	case typ.size == 1:
+		return float64(b[0]), nil
+	case typ.size == 2 && typ.signed:
+		return float64(int16(p.order.Uint16(b))), nil
+	case typ.size == 2:
+		return float64(p.order.Uint16(b)), nil
+	case typ.signed:
+		return float64(int32(p.order.Uint32(b))), nil
+	default:
+		return float64(p.order.Uint32(b)), nil
+	}
+}
+
+// plyColor scales a color channel of the given type to 8 bits
+func plyColor(v float64, typ plyType) uint8 {
+	return uint8(math.Round(255 * max(0, min(1, v/typ.maxColor))))
+}
+
+// solid builds the Triangles from the faces read
+func (p *plyParser) solid() (Solid, error) {
+	s := Solid{Header: p.header}
+	colored := len(p.colors) == len(p.vertices) && len(p.colors) > 0
+	for _, f := range p.faces {
+		if f.hasColor {
+			colored = true
+		}
+	}
+	if colored {
+		s.ColorFormat = ColorVisCAM
+	}
+
+	for i, f := range p.faces {
+		for _, v := range f.verts {
+			if v < 0 || v >= len(p.vertices) {
+				return Solid{}, &ParseError{Format: FormatPLY, Facet: i, Offset: p.offset(),
+					Err: fmt.Errorf("%w: vertex index %d out of range, %d defined", ErrSyntax, v, len(p.vertices))}
+			}
+		}
+
+		for j := 1; j+1 < len(f.verts); j++ {
+			corners := [3]int{f.verts[0], f.verts[j], f.verts[j+1]}
+			t := Triangle{}
+			for k, v := range corners {
+				t.Vertices[k] = p.vertices[v]
+			}
+
+			t.Normal = t.ComputeNormal()
+			if t.Normal == (UnitVector{}) && len(p.normals) == len(p.vertices) {
+				var n Vec3
+				for _, v := range corners {
+					n = n.Add(p.normals[v])
+				}
+				t.Normal = n.Normalize().UnitVector()
+			}
+
+			switch {
+			case f.hasColor:
+				t.SetColor(ColorVisCAM, f.color)
+			case colored && len(p.colors) == len(p.vertices):
+				var r, g, b int
+				for _, v := range corners {
+					r, g, b = r+int(p.colors[v].R), g+int(p.colors[v].G), b+int(p.colors[v].B)
+				}
+				t.SetColor(ColorVisCAM, Color{R: uint8((r + 1) / 3), G: uint8((g + 1) / 3), B: uint8((b + 1) / 3), A: 255})
+			}
+
+			s.Triangles = append(s.Triangles, t)
+		}
+	}
+	if len(s.Triangles) == 0 {
+		return Solid{}, &ParseError{Format: FormatPLY, Facet: -1, Err: fmt.Errorf("%w: no faces", ErrEmpty)}
+	}
+	s.TriangleCount = uint32(len(s.Triangles))
+
+	return s, nil
+}
@@ -0,0 +1,167 @@
+package stl
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+)
+
+func TestFromPLY(t *testing.T) {
+	colored := `ply
+format ascii 1.0
+comment scanned part
+comment second comment
+element vertex 4
+property float x
+property float y
+property float z
+property float confidence
+property uchar red
+property uchar green
+property uchar blue
+element face 1
+property list uchar int vertex_indices
+property list uchar float texcoord
+element material 1
+property uchar kind
+end_header
+0 0 0 0.5 255 0 0
+1 0 0 0.5 255 0 0
+1 1 0 0.5 0 0 255
+0 1 0 0.5 0 0 255
+4 0 1 2 3 2 0.5 0.5
+7
+`
+	s, err := FromPLY(strings.NewReader(colored))
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if s.Header != "scanned part" || len(s.Triangles) != 2 || s.TriangleCount != 2 || s.ColorFormat != ColorVisCAM {
+		t.Fatalf("got %q with %d triangles, count %d, %s colors; want %q, 2, VisCAM", s.Header, len(s.Triangles), s.TriangleCount, s.ColorFormat, "scanned part")
+	}
+	if a := s.SurfaceArea(); !near(a, 1) {
+		t.Errorf("got area %v; want 1", a)
+	}
+	if bad := s.BadNormals(1e-6); len(bad) > 0 {
+		t.Errorf("got bad normals %v", bad)
+	}
+
+	// Two red corners and one blue, and one red and two blue
+	for i, want := range []Color{{R: 173, B: 82, A: 255}, {R: 82, B: 173, A: 255}} {
+		if c, ok := s.FacetColor(i); !ok || c != want {
+			t.Errorf("got triangle %d color %v, %t; want %v", i, c, ok, want)
+		}
+	}
+
+	// Face colors win over vertex colors
+	faceColored := strings.Replace(colored, "property list uchar float texcoord\n", "property list uchar float texcoord\nproperty uchar red\nproperty uchar green\nproperty uchar blue\n", 1)
+	faceColored = strings.Replace(faceColored, "4 0 1 2 3 2 0.5 0.5\n", "4 0 1 2 3 2 0.5 0.5 0 255 0\n", 1)
+	s, err = FromPLY(strings.NewReader(faceColored))
+	if err != nil {
+		t.Fatalf("could not read: %v", err)
+	}
+	if c, ok := s.FacetColor(1); !ok || c != (Color{G: 255, A: 255}) {
+		t.Errorf("got face color %v, %t; want green", c, ok)
+	}
+}
+func TestFromPLY_Binary(t *testing.T) {
+	for _, order := range []binary.AppendByteOrder{binary.LittleEndian, binary.BigEndian} {
+		name := "binary_little_endian"
+		if order == binary.BigEndian {
+			name = "binary_big_endian"
+		}
+
+		// A triangle with double coordinates, short vertex colors, and ushort indices
+		var buf bytes.Buffer
+		buf.WriteString("ply\r\nformat " + name + " 1.0\r\nelement vertex 3\r\nproperty double x\r\nproperty double y\r\nproperty double z\r\n" +
+			"property ushort red\r\nproperty ushort green\r\nproperty ushort blue\r\n" +
+			"element face 1\r\nproperty list int ushort vertex_index\r\nend_header\r\n")
+		for _, v := range [][3]float64{{0, 0, 0}, {2, 0, 0}, {0, 2, 0}} {
+			for _, f := range v {
+				buf.Write(order.AppendUint64(nil, math.Float64bits(f)))
+			}
+			buf.Write(order.AppendUint16(nil, 65535))
+			buf.Write(order.AppendUint16(nil, 0))
+			buf.Write(order.AppendUint16(nil, 0))
+		}
+		buf.Write(order.AppendUint32(nil, 3))
+		for _, i := range []uint16{0, 1, 2} {
+			buf.Write(order.AppendUint16(nil, i))
+		}
+
+		s, err := FromPLY(&buf)
+		if err != nil {
+			t.Fatalf("could not read %s: %v", name, err)
+		}
+		if len(s.Triangles) != 1 || s.Triangles[0].Vertices[1] != (Coordinate{X: 2}) || !near(s.SurfaceArea(), 2) {
+			t.Errorf("got %s triangles %v; want one of area 2", name, s.Triangles)
+		}
+		if c, ok := s.FacetColor(0); !ok || c != (Color{R: 255, A: 255}) {
+			t.Errorf("got %s color %v, %t; want red", name, c, ok)
+		}
+	}
+}
+func TestFromPLY_Errors(t *testing.T) {
+	header := "ply\nformat ascii 1.0\nelement vertex 3\nproperty float x\nproperty float y\nproperty float z\nelement face 1\nproperty list uchar int vertex_indices\nend_header\n"
+	body := "0 0 0\n1 0 0\n0 1 0\n"
+
+	for _, tst := range []struct {
+		name      string
+		in        string
+		opts      ReadOptions
+		want      error
+		wantLine  int
+		wantFacet int
+	}{
+		{name: "empty", in: "", want: ErrEmpty, wantFacet: -1},
+		{name: "no faces", in: strings.Replace(header, "element face 1\n", "element face 0\n", 1) + body, want: ErrEmpty, wantFacet: -1},
+		{name: "no vertex_indices", in: strings.Replace(header, "property list uchar int vertex_indices", "property list uchar int texcoord", 1) + body + "3 0 1 2\n", want: ErrEmpty, wantFacet: -1},
+		{name: "not PLY", in: "solid x\n", want: ErrSyntax, wantLine: 1, wantFacet: -1},
+		{name: "no end_header", in: "ply\nformat ascii 1.0\n", want: ErrTruncated, wantLine: 2, wantFacet: -1},
+		{name: "no format", in: "ply\nend_header\n", want: ErrSyntax, wantLine: 2, wantFacet: -1},
+		{name: "bad format", in: "ply\nformat xml 1.0\n", want: ErrSyntax, wantLine: 2, wantFacet: -1},
+		{name: "bad type", in: "ply\nformat ascii 1.0\nelement vertex 1\nproperty float3 x\n", want: ErrSyntax, wantLine: 4, wantFacet: -1},
+		{name: "property first", in: "ply\nformat ascii 1.0\nproperty float x\n", want: ErrSyntax, wantLine: 3, wantFacet: -1},
+		{name: "truncated", in: header + body, want: ErrTruncated, wantLine: 12, wantFacet: 0},
+		{name: "bad value", in: header + "0 0 0\n1 x 0\n", want: ErrSyntax, wantLine: 11, wantFacet: -1},
+		{name: "extra value", in: header + body + "3 0 1 2 9\n", want: ErrSyntax, wantLine: 13, wantFacet: 0},
+		{name: "two vertices", in: header + body + "2 0 1\n", want: ErrSyntax, wantLine: 13, wantFacet: 0},
+		{name: "bad index", in: header + body + "3 0 1 3\n", want: ErrSyntax, wantFacet: 0},
+		{name: "too many triangles", in: header + body + "4 0 1 2 0\n", opts: ReadOptions{MaxTriangles: 1}, want: ErrTooManyTriangles, wantLine: 13, wantFacet: 0},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			_, err := FromPLYContext(context.Background(), strings.NewReader(tst.in), tst.opts)
+			if !errors.Is(err, tst.want) {
+				t.Fatalf("got %v; want %v", err, tst.want)
+			}
+
+			var perr *ParseError
+			if !errors.As(err, &perr) {
+				t.Fatalf("got %T; want *ParseError", err)
+			}
+			if perr.Format != FormatPLY || perr.Line != tst.wantLine || perr.Facet != tst.wantFacet {
+				t.Errorf("got %s line %d facet %d; want PLY line %d facet %d", perr.Format, perr.Line, perr.Facet, tst.wantLine, tst.wantFacet)
+			}
+		})
+	}
+
+	// Binary input cut off part way through a face
+	var buf bytes.Buffer
+	binHeader := strings.Replace(header, "ascii", "binary_little_endian", 1)
+	buf.WriteString(binHeader)
+	for i := 0; i < 9; i++ {
+		buf.Write(binary.LittleEndian.AppendUint32(nil, 0))
+	}
+	buf.WriteByte(3)
+	_, err := FromPLY(&buf)
+	var perr *ParseError
+	if !errors.Is(err, ErrTruncated) || !errors.As(err, &perr) || perr.Line != 0 || perr.Facet != 0 || perr.Offset != int64(len(binHeader)+37) {
+		t.Errorf("got %v; want truncated at facet 0, byte %d", err, len(binHeader)+37)
+	}
+}
@@ -0,0 +1,419 @@
+package stl
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFromContext(t *testing.T) {
+	solid := testSolid()
+	ascii, binary := &bytes.Buffer{}, &bytes.Buffer{}
+	if err := solid.ToASCII(ascii); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	if err := solid.ToBinary(binary); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	for _, tst := range []struct {
+		ctx  context.Context
+		in   []byte
+		opts ReadOptions
+		want error
+	}{
+		{
+			ctx:  context.Background(),
+			in:   ascii.Bytes(),
+			opts: ReadOptions{MaxTriangles: 3, MaxBytes: int64(ascii.Len())},
+			want: nil,
+		},
+		{
+			ctx:  context.Background(),
+			in:   binary.Bytes(),
+			opts: ReadOptions{MaxTriangles: 3, MaxBytes: int64(binary.Len())},
+			want: nil,
+		},
+		{
+			ctx:  cancelled,
+			in:   ascii.Bytes(),
+			want: context.Canceled,
+		},
+		{
+			ctx:  cancelled,
+			in:   binary.Bytes(),
+			want: context.Canceled,
+		},
+		{
+			ctx:  context.Background(),
+			in:   ascii.Bytes(),
+			opts: ReadOptions{MaxTriangles: 2},
+			want: ErrTooManyTriangles,
+		},
+		{
+			ctx:  context.Background(),
+			in:   binary.Bytes(),
+			opts: ReadOptions{MaxTriangles: 2},
+			want: ErrTooManyTriangles,
+		},
+		{
+			ctx:  context.Background(),
+			in:   ascii.Bytes(),
+			opts: ReadOptions{MaxBytes: int64(ascii.Len() - 1)},
+			want: ErrTooLarge,
+		},
+		{
+			ctx:  context.Background(),
+			in:   binary.Bytes(),
+			opts: ReadOptions{MaxBytes: 100},
+			want: ErrTooLarge,
+		},
+	} {
+		tst := tst
+		t.Run(fmt.Sprintf("%v %+v", tst.want, tst.opts), func(t *testing.T) {
+			t.Parallel()
+			got, err := FromContext(tst.ctx, bytes.NewReader(tst.in), tst.opts)
+			if !errors.Is(err, tst.want) {
+				t.Fatalf("got %v; want %v", err, tst.want)
+			}
+			if err == nil && len(got.Triangles) != len(solid.Triangles) {
+				t.Errorf("got %d triangles; want %d", len(got.Triangles), len(solid.Triangles))
+			}
+		})
+	}
+}
+func TestFromContext_Options(t *testing.T) {
+	solid := testSolid()
+	binary := &bytes.Buffer{}
+	if err := solid.ToBinary(binary); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	// Trailing partial triangle
+	partial := append(append([]byte{}, binary.Bytes()...), 1, 2, 3)
+	// Binary header that looks like ASCII
+	solidHeader := append([]byte{}, binary.Bytes()...)
+	copy(solidHeader, "solid ")
+	// Same, with a header that is all text
+	textHeader := append([]byte{}, binary.Bytes()...)
+	copy(textHeader, "solid "+strings.Repeat(" ", 74))
+	ascii := &bytes.Buffer{}
+	if err := solid.ToASCII(ascii); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	// Same ASCII with tabs, CRLF line endings, blank lines and upper case keywords
+	loose := strings.NewReplacer(" ", "\t ", "\n", "\r\n\r\n", "facet", "FACET", "vertex", "Vertex").Replace(ascii.String())
+	noEnd := ascii.String()[:strings.LastIndex(ascii.String(), "endsolid")]
+
+	for _, tst := range []struct {
+		name    string
+		in      []byte
+		opts    ReadOptions
+		wantErr bool
+	}{
+		{
+			name: "one worker, one triangle per chunk",
+			in:   binary.Bytes(),
+			opts: ReadOptions{Workers: 1, ChunkSize: 50, BufferSize: 16},
+		},
+		{
+			name: "chunk size rounded down",
+			in:   binary.Bytes(),
+			opts: ReadOptions{ChunkSize: 99},
+		},
+		{
+			name: "loose ASCII, one facet per chunk",
+			in:   []byte(loose),
+			opts: ReadOptions{Workers: 2, ChunkSize: 1, BufferSize: 16},
+		},
+		{
+			name: "loose ASCII",
+			in:   []byte(loose),
+		},
+		{
+			name:    "ASCII missing endsolid",
+			in:      []byte(noEnd),
+			wantErr: true,
+		},
+		{
+			name: "ASCII missing endsolid, lenient",
+			in:   []byte(noEnd),
+			opts: ReadOptions{Lenient: true, ChunkSize: 1},
+		},
+		{
+			name:    "ASCII data after endsolid",
+			in:      []byte(ascii.String() + "solid again\n"),
+			opts:    ReadOptions{ChunkSize: 1},
+			wantErr: true,
+		},
+		{
+			name:    "partial triangle",
+			in:      partial,
+			wantErr: true,
+		},
+		{
+			name: "partial triangle, lenient",
+			in:   partial,
+			opts: ReadOptions{Lenient: true},
+		},
+		{
+			name: "binary header like ASCII, small buffer",
+			in:   textHeader,
+			opts: ReadOptions{BufferSize: 16},
+		},
+		{
+			name: "forced binary",
+			in:   solidHeader,
+			opts: ReadOptions{Format: FormatBinary},
+		},
+		{
+			name:    "forced ASCII",
+			in:      solidHeader,
+			opts:    ReadOptions{Format: FormatASCII},
+			wantErr: true,
+		},
+		{
+			name:    "forced OBJ",
+			in:      binary.Bytes(),
+			opts:    ReadOptions{Format: FormatOBJ},
+			wantErr: true,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := FromContext(context.Background(), bytes.NewReader(tst.in), tst.opts)
+			if (err != nil) != tst.wantErr {
+				t.Fatalf("got %v; want error %t", err, tst.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Triangles) != len(solid.Triangles) {
+				t.Fatalf("got %d triangles; want %d", len(got.Triangles), len(solid.Triangles))
+			}
+			for i := range got.Triangles {
+				if got.Triangles[i] != solid.Triangles[i] {
+					t.Errorf("got %+v for triangle %d; want %+v", got.Triangles[i], i, solid.Triangles[i])
+				}
+			}
+		})
+	}
+}
+func TestFromMultiContext(t *testing.T) {
+	solid := testSolid()
+	binary, single, multi := &bytes.Buffer{}, &bytes.Buffer{}, &bytes.Buffer{}
+	if err := solid.ToBinary(binary); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	if err := solid.ToASCII(single); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	parts := []Solid{
+		{Header: "a", Triangles: solid.Triangles[:1]},
+		{Header: "b", Triangles: solid.Triangles[1:]},
+	}
+	if err := ToASCIIMulti(multi, parts); err != nil {
+		t.Fatalf("could not write solids: %v", err)
+	}
+
+	for _, tst := range []struct {
+		name string
+		in   []byte
+		opts ReadOptions
+		want []string
+	}{
+		{
+			name: "binary",
+			in:   binary.Bytes(),
+			want: []string{"test"},
+		},
+		{
+			name: "single ASCII solid",
+			in:   single.Bytes(),
+			want: []string{"test"},
+		},
+		{
+			name: "multiple ASCII solids",
+			in:   multi.Bytes(),
+			want: []string{"a", "b"},
+		},
+		{
+			name: "multiple ASCII solids, one facet per chunk",
+			in:   multi.Bytes(),
+			opts: ReadOptions{ChunkSize: 1, Workers: 3},
+			want: []string{"a", "b"},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			got, err := FromMultiContext(context.Background(), bytes.NewReader(tst.in), tst.opts)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(got) != len(tst.want) {
+				t.Fatalf("got %d solids; want %d", len(got), len(tst.want))
+			}
+
+			// Triangles are split between the solids in order
+			var tris []Triangle
+			for i, s := range got {
+				if !strings.HasPrefix(s.Header, tst.want[i]) {
+					t.Errorf("got header %q; want %q", s.Header, tst.want[i])
+				}
+				tris = append(tris, s.Triangles...)
+			}
+			if len(tris) != len(solid.Triangles) {
+				t.Fatalf("got %d triangles; want %d", len(tris), len(solid.Triangles))
+			}
+			for i := range tris {
+				if tris[i] != solid.Triangles[i] {
+					t.Errorf("got %+v for triangle %d; want %+v", tris[i], i, solid.Triangles[i])
+				}
+			}
+		})
+	}
+
+	// Reading multiple solids as one is only allowed when lenient
+	if _, err := From(bytes.NewReader(multi.Bytes())); !errors.Is(err, ErrSyntax) {
+		t.Errorf("got %v; want %v", err, ErrSyntax)
+	}
+	merged, err := FromContext(context.Background(), bytes.NewReader(multi.Bytes()), ReadOptions{Lenient: true})
+	if err != nil || len(merged.Triangles) != len(solid.Triangles) {
+		t.Errorf("got %d triangles, %v; want %d", len(merged.Triangles), err, len(solid.Triangles))
+	}
+}
+func TestFromContext_CountMismatch(t *testing.T) {
+	solid := testSolid()
+	buf := &bytes.Buffer{}
+	if err := solid.ToBinary(buf); err != nil {
+		t.Fatalf("could not write solid: %v", err)
+	}
+	withCount := func(count uint32, extra ...byte) []byte {
+		in := append(append([]byte{}, buf.Bytes()...), extra...)
+		copy(in[80:84], triCountBinary(count))
+		return in
+	}
+
+	for _, tst := range []struct {
+		name         string
+		in           []byte
+		opts         ReadOptions
+		wantErr      error
+		wantTris     int
+		wantCount    int
+		wantWarnings []error
+	}{
+		{
+			name:         "fewer triangles than declared",
+			in:           withCount(4),
+			wantTris:     3,
+			wantCount:    4,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:         "more triangles than declared",
+			in:           withCount(2),
+			wantTris:     3,
+			wantCount:    2,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:    "fewer triangles than declared, strict",
+			in:      withCount(4),
+			opts:    ReadOptions{StrictCount: true},
+			wantErr: ErrCountMismatch,
+		},
+		{
+			name:    "more triangles than declared, strict",
+			in:      withCount(2),
+			opts:    ReadOptions{StrictCount: true},
+			wantErr: ErrCountMismatch,
+		},
+		{
+			name:         "fewer triangles than declared, lenient",
+			in:           withCount(4),
+			opts:         ReadOptions{Lenient: true, StrictCount: true},
+			wantTris:     3,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:         "declared count of zero, lenient",
+			in:           withCount(0),
+			opts:         ReadOptions{Lenient: true},
+			wantTris:     3,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:         "partial triangle, lenient",
+			in:           withCount(3, 1, 2, 3),
+			opts:         ReadOptions{Lenient: true},
+			wantTris:     3,
+			wantWarnings: []error{ErrTruncated},
+		},
+		{
+			name:         "padding",
+			in:           withCount(3, make([]byte, 120)...),
+			opts:         ReadOptions{IgnoreTrailing: true},
+			wantTris:     3,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:         "extra triangles ignored",
+			in:           withCount(2),
+			opts:         ReadOptions{IgnoreTrailing: true, ChunkSize: 50},
+			wantTris:     2,
+			wantWarnings: []error{ErrCountMismatch},
+		},
+		{
+			name:    "fewer triangles than declared, ignoring trailing data",
+			in:      withCount(4),
+			opts:    ReadOptions{IgnoreTrailing: true, StrictCount: true},
+			wantErr: ErrCountMismatch,
+		},
+		{
+			name:     "no warnings for a good input",
+			in:       withCount(3),
+			opts:     ReadOptions{Lenient: true, IgnoreTrailing: true},
+			wantTris: 3,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			var warnings []error
+			tst.opts.OnWarning = func(err error) { warnings = append(warnings, err) }
+
+			got, err := FromContext(context.Background(), bytes.NewReader(tst.in), tst.opts)
+			if !errors.Is(err, tst.wantErr) || (err == nil) != (tst.wantErr == nil) {
+				t.Fatalf("got %v; want %v", err, tst.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			wantCount := tst.wantCount
+			if wantCount == 0 {
+				wantCount = tst.wantTris
+			}
+			if len(got.Triangles) != tst.wantTris || got.TriangleCount != uint32(wantCount) {
+				t.Errorf("got %d triangles with count %d; want %d with count %d", len(got.Triangles), got.TriangleCount, tst.wantTris, wantCount)
+			}
+
+			if len(warnings) != len(tst.wantWarnings) {
+				t.Fatalf("got warnings %v; want %v", warnings, tst.wantWarnings)
+			}
+			for i := range warnings {
+				var pErr *ParseError
+				if !errors.As(warnings[i], &pErr) || !errors.Is(warnings[i], tst.wantWarnings[i]) {
+					t.Errorf("got warning %v; want a *ParseError for %v", warnings[i], tst.wantWarnings[i])
+				}
+			}
+		})
+	}
+}
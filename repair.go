@@ -0,0 +1,368 @@
+package stl
+
+import (
+	"math"
+	"sort"
+)
+
+// Holes with more edges than this are left open by default
+const defaultMaxHoleEdges = 64
+
+// RepairOptions controls Solid.Repair
+type RepairOptions struct {
+	// Tolerance welds vertices within this distance of each other.  Zero only welds identical vertices.
+	Tolerance float64
+	// MaxHoleEdges is the most edges a hole may have to be filled.  Zero is 64, and
+	// a negative value fills no holes.
+	MaxHoleEdges int
+}
+
+// RepairReport counts every change made by Solid.Repair
+type RepairReport struct {
+	// Welded is the number of vertices merged into a nearby one
+	Welded int
+	// Degenerate is the number of Triangles removed for having no area
+	Degenerate int
+	// Duplicates is the number of Triangles removed for having the same vertices as another
+	Duplicates int
+	// Reoriented is the number of Triangles flipped to agree with their neighbors
+	Reoriented int
+	// InsideOut is the number of closed shells flipped to face outward
+	InsideOut int
+	// HolesFilled is the number of holes closed, using FillTriangles new Triangles
+	HolesFilled   int
+	FillTriangles int
+	// HolesLeft is the number of holes too large or too tangled to fill
+	HolesLeft int
+}
+
+// Changed reports whether Repair changed anything
+func (r RepairReport) Changed() bool {
+	return r != RepairReport{}
+}
+
+// Repair fixes the problems found by Validate where it can.
+// In order, it welds vertices, removes degenerate and duplicate Triangles, flips
+// Triangles to agree with their neighbors, fills holes by triangulating their
+// boundary loops, and turns inside-out shells the right way round.
+// Flipped and new Triangles get normals from their winding.
+// Non-manifold edges and vertices are left as they are.
+func (s *Solid) Repair(opts RepairOptions) RepairReport {
+	var r RepairReport
+	if opts.MaxHoleEdges == 0 {
+		opts.MaxHoleEdges = defaultMaxHoleEdges
+	}
+
+	// Only count vertices that moved, not corners that were already shared
+	mesh, _ := s.Indexed(0)
+	if opts.Tolerance > 0 {
+		r.Welded = mesh.Weld(opts.Tolerance)
+	}
+
+	r.Degenerate, r.Duplicates = removeBadFaces(&mesh)
+
+	// changed marks faces that need a new normal
+	changed := make([]bool, len(mesh.Faces))
+	r.Reoriented = orientFaces(&mesh, changed)
+
+	filled := len(mesh.Faces)
+	if opts.MaxHoleEdges > 0 {
+		fill, left := fillHoles(&mesh, opts.MaxHoleEdges)
+		r.HolesLeft = left
+		for _, f := range fill {
+			r.HolesFilled++
+			r.FillTriangles += len(f)
+			for _, v := range f {
+				mesh.Faces = append(mesh.Faces, Face{Vertices: v})
+				changed = append(changed, true)
+			}
+		}
+	}
+
+	r.InsideOut = flipInsideOut(&mesh, changed)
+
+	// Keep what is needed to read the colors in AttrByteCnt
+	format, color, material := s.ColorFormat, s.Color, s.Material
+	*s = mesh.Solid()
+	s.ColorFormat, s.Color, s.Material = format, color, material
+	for i := range s.Triangles {
+		if changed[i] {
+			s.Triangles[i].Normal = s.Triangles[i].ComputeNormal()
+		}
+		if i >= filled {
+			s.Triangles[i].ClearColor(format)
+		}
+	}
+
+	return r
+}
+
+// removeBadFaces removes faces that have no area or repeat an earlier face
+func removeBadFaces(mesh *IndexedMesh) (degenerate, duplicates int) {
+	seen := make(map[[3]int]bool, len(mesh.Faces))
+	kept := mesh.Faces[:0]
+	for _, f := range mesh.Faces {
+		v := f.Vertices
+		a, b, c := mesh.Vertices[v[0]].Vec3(), mesh.Vertices[v[1]].Vec3(), mesh.Vertices[v[2]].Vec3()
+		if b.Sub(a).Cross(c.Sub(a)).Len() == 0 {
+			degenerate++
+			continue
+		}
+
+		key := sortedTriple(v)
+		if seen[key] {
+			duplicates++
+			continue
+		}
+		seen[key] = true
+
+		kept = append(kept, f)
+	}
+	mesh.Faces = kept
+
+	return degenerate, duplicates
+}
+
+// orientFaces flips faces so that neighbors across each manifold edge wind it in
+// opposite directions.  Within each connected group the fewest faces are flipped.
+func orientFaces(mesh *IndexedMesh, changed []bool) int {
+	adj := make([][]int, len(mesh.Faces))
+	for _, e := range meshEdges(*mesh, make([]bool, len(mesh.Faces))) {
+		if len(e.uses) == 2 {
+			a, b := e.uses[0].face, e.uses[1].face
+			adj[a] = append(adj[a], b)
+			adj[b] = append(adj[b], a)
+		}
+	}
+
+	flipped := 0
+	seen := make([]bool, len(mesh.Faces))
+	for start := range mesh.Faces {
+		if seen[start] {
+			continue
+		}
+
+		// Walk the group, flipping any face that winds a shared edge the same way as the face before it
+		seen[start] = true
+		group, flips := []int{start}, []int{}
+		for i := 0; i < len(group); i++ {
+			f := group[i]
+			for _, g := range adj[f] {
+				if seen[g] {
+					continue
+				}
+				seen[g] = true
+				if sameWinding(mesh.Faces[f], mesh.Faces[g]) {
+					flipFace(&mesh.Faces[g])
+					flips = append(flips, g)
+				}
+				group = append(group, g)
+			}
+		}
+
+		// Flipping the other faces instead is fewer changes
+		if 2*len(flips) > len(group) {
+			isFlipped := make(map[int]bool, len(flips))
+			for _, f := range flips {
+				isFlipped[f] = true
+			}
+			flips = flips[:0]
+			for _, f := range group {
+				flipFace(&mesh.Faces[f])
+				if !isFlipped[f] {
+					flips = append(flips, f)
+				}
+			}
+		}
+
+		for _, f := range flips {
+			changed[f] = !changed[f]
+		}
+		flipped += len(flips)
+	}
+
+	return flipped
+}
+
+// sameWinding reports whether f and g share an edge that they wind in the same direction
+func sameWinding(f, g Face) bool {
+	for i := 0; i < 3; i++ {
+		a, b := f.Vertices[i], f.Vertices[(i+1)%3]
+		for j := 0; j < 3; j++ {
+			if g.Vertices[j] == a && g.Vertices[(j+1)%3] == b {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+func flipFace(f *Face) {
+	f.Vertices[1], f.Vertices[2] = f.Vertices[2], f.Vertices[1]
+}
+
+// fillHoles triangulates each loop of open edges with at most maxEdges edges.
+// It returns the new faces for each filled hole, and how many holes were left open.
+func fillHoles(mesh *IndexedMesh, maxEdges int) (fill [][][3]int, left int) {
+	// A hole runs around its boundary in the opposite direction to the faces beside it
+	next := make(map[int]int)
+	tangled := make(map[int]bool)
+	for _, e := range meshEdges(*mesh, make([]bool, len(mesh.Faces))) {
+		if len(e.uses) != 1 {
+			continue
+		}
+		from, to := e.b, e.a
+		if !e.uses[0].forward {
+			from, to = e.a, e.b
+		}
+		if _, ok := next[from]; ok {
+			tangled[from] = true
+		}
+		next[from] = to
+	}
+
+	// Map iteration is random, so start loops in vertex order
+	starts := make([]int, 0, len(next))
+	for v := range next {
+		starts = append(starts, v)
+	}
+	sort.Ints(starts)
+
+	visited := make(map[int]bool, len(next))
+	for _, start := range starts {
+		if visited[start] {
+			continue
+		}
+
+		var loop []int
+		ok := true
+		for v := start; ; {
+			if v == start && len(loop) > 0 {
+				break
+			}
+			to, has := next[v]
+			if visited[v] || !has || tangled[v] {
+				ok = false
+				break
+			}
+			visited[v] = true
+			loop = append(loop, v)
+			v = to
+		}
+
+		if !ok || len(loop) < 3 || len(loop) > maxEdges {
+			left++
+			continue
+		}
+		fill = append(fill, triangulateLoop(mesh.Vertices, loop))
+	}
+
+	return fill, left
+}
+
+// triangulateLoop fills a loop of vertices with triangles wound in the same
+// direction as the loop, by clipping ears in the plane that best fits it
+func triangulateLoop(verts []Coordinate, loop []int) [][3]int {
+	// Newell's method gives a normal the loop runs counter-clockwise around
+	var n Vec3
+	for i := range loop {
+		a, b := verts[loop[i]].Vec3(), verts[loop[(i+1)%len(loop)]].Vec3()
+		n.X += (a.Y - b.Y) * (a.Z + b.Z)
+		n.Y += (a.Z - b.Z) * (a.X + b.X)
+		n.Z += (a.X - b.X) * (a.Y + b.Y)
+	}
+	u, v := planeBasis(n)
+
+	pts := make([][2]float64, len(loop))
+	for i, vi := range loop {
+		p := verts[vi].Vec3()
+		pts[i] = [2]float64{p.Dot(u), p.Dot(v)}
+	}
+
+	var tris [][3]int
+	idx := make([]int, len(loop))
+	for i := range idx {
+		idx[i] = i
+	}
+	for len(idx) > 3 {
+		ear := -1
+		for i := range idx {
+			a, b, c := idx[(i+len(idx)-1)%len(idx)], idx[i], idx[(i+1)%len(idx)]
+			if cross2(pts[a], pts[b], pts[c]) <= 0 {
+				continue
+			}
+
+			inside := false
+			for _, o := range idx {
+				if o != a && o != b && o != c && inTriangle2(pts[o], pts[a], pts[b], pts[c]) {
+					inside = true
+					break
+				}
+			}
+			if !inside {
+				ear = i
+				break
+			}
+		}
+
+		// A loop too twisted to have an ear is filled with a fan instead
+		if ear < 0 {
+			break
+		}
+
+		a, b, c := idx[(ear+len(idx)-1)%len(idx)], idx[ear], idx[(ear+1)%len(idx)]
+		tris = append(tris, [3]int{loop[a], loop[b], loop[c]})
+		idx = append(idx[:ear], idx[ear+1:]...)
+	}
+	for i := 1; i+1 < len(idx); i++ {
+		tris = append(tris, [3]int{loop[idx[0]], loop[idx[i]], loop[idx[i+1]]})
+	}
+
+	return tris
+}
+
+// planeBasis is a pair of unit vectors u and v in the plane with normal n, where u × v points along n
+func planeBasis(n Vec3) (Vec3, Vec3) {
+	n = n.Normalize()
+
+	// Start from the axis least aligned with n
+	axis := Vec3{X: 1}
+	if math.Abs(n.X) > math.Abs(n.Y) || math.Abs(n.X) > math.Abs(n.Z) {
+		axis = Vec3{Y: 1}
+		if math.Abs(n.Y) > math.Abs(n.Z) {
+			axis = Vec3{Z: 1}
+		}
+	}
+
+	u := axis.Cross(n).Normalize()
+	return u, n.Cross(u)
+}
+
+// cross2 is the z of the cross product of b-a and c-b, positive for a left turn
+func cross2(a, b, c [2]float64) float64 {
+	return (b[0]-a[0])*(c[1]-b[1]) - (b[1]-a[1])*(c[0]-b[0])
+}
+
+// inTriangle2 reports whether p is inside or on the counter-clockwise triangle a, b, c
+func inTriangle2(p, a, b, c [2]float64) bool {
+	return cross2(a, b, p) >= 0 && cross2(b, c, p) >= 0 && cross2(c, a, p) >= 0
+}
+
+// flipInsideOut flips every closed shell that faces the wrong way for its depth,
+// and returns how many were flipped
+func flipInsideOut(mesh *IndexedMesh, changed []bool) int {
+	flipped := 0
+	for _, sh := range meshShells(*mesh, edgeComponents(*mesh, make([]bool, len(mesh.Faces)))) {
+		if !sh.Closed || sh.Outward() {
+			continue
+		}
+
+		for _, f := range sh.Triangles {
+			flipFace(&mesh.Faces[f])
+			changed[f] = !changed[f]
+		}
+		flipped++
+	}
+
+	return flipped
+}
@@ -0,0 +1,201 @@
+package stl
+
+import "testing"
+
+func TestSolid_Repair(t *testing.T) {
+	box := func() Solid { return testBox(1, 2, 3) }
+
+	open := box()
+	open.Triangles = open.Triangles[1:]
+
+	// The whole bottom face missing leaves a four edge hole
+	bottom := box()
+	bottom.Triangles = bottom.Triangles[2:]
+
+	flipped := box()
+	v := &flipped.Triangles[2].Vertices
+	v[1], v[2] = v[2], v[1]
+
+	insideOut := box()
+	for i := range insideOut.Triangles {
+		v := &insideOut.Triangles[i].Vertices
+		v[1], v[2] = v[2], v[1]
+	}
+
+	duplicate := box()
+	duplicate.Triangles = append(duplicate.Triangles, duplicate.Triangles[4])
+
+	degenerate := box()
+	degenerate.Triangles = append(degenerate.Triangles, Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 1}}})
+
+	// A corner nudged apart, so it only closes once welded
+	nudged := box()
+	nudged.Triangles[11].Vertices[2] = Coordinate{X: 1 + 1e-4, Z: 3}
+
+	// Magics colors, with a default for the triangles without their own
+	colored := box()
+	colored.Triangles = colored.Triangles[1:]
+	colored.ColorFormat = ColorMagics
+	colored.Color = &Color{G: 255, A: 255}
+	colored.Material = &Material{}
+	for i := range colored.Triangles {
+		colored.Triangles[i].ClearColor(ColorMagics)
+	}
+	colored.Triangles[0].SetColor(ColorMagics, Color{R: 255, A: 255})
+
+	for _, tst := range []struct {
+		name       string
+		in         Solid
+		opts       RepairOptions
+		want       RepairReport
+		wantTris   int
+		wantVolume float64
+	}{
+		{
+			name:       "closed box",
+			in:         box(),
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "missing triangle",
+			in:         open,
+			want:       RepairReport{HolesFilled: 1, FillTriangles: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "missing face",
+			in:         bottom,
+			want:       RepairReport{HolesFilled: 1, FillTriangles: 2},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "hole too large",
+			in:         bottom,
+			opts:       RepairOptions{MaxHoleEdges: 3},
+			want:       RepairReport{HolesLeft: 1},
+			wantTris:   10,
+			wantVolume: 6,
+		},
+		{
+			name:       "flipped triangle",
+			in:         flipped,
+			want:       RepairReport{Reoriented: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "inside out",
+			in:         insideOut,
+			want:       RepairReport{InsideOut: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "void",
+			in:         boxWithVoid(false),
+			wantTris:   24,
+			wantVolume: 56,
+		},
+		{
+			name:       "void facing out",
+			in:         boxWithVoid(true),
+			want:       RepairReport{InsideOut: 1},
+			wantTris:   24,
+			wantVolume: 56,
+		},
+		{
+			name:       "duplicate",
+			in:         duplicate,
+			want:       RepairReport{Duplicates: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "degenerate",
+			in:         degenerate,
+			want:       RepairReport{Degenerate: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "colored",
+			in:         colored,
+			want:       RepairReport{HolesFilled: 1, FillTriangles: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+		{
+			name:       "welded",
+			in:         nudged,
+			opts:       RepairOptions{Tolerance: 1e-3},
+			want:       RepairReport{Welded: 1},
+			wantTris:   12,
+			wantVolume: 6,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			s := tst.in
+			s.Triangles = append([]Triangle(nil), tst.in.Triangles...)
+
+			r := s.Repair(tst.opts)
+			if r != tst.want {
+				t.Errorf("got report %+v; want %+v", r, tst.want)
+			}
+			if len(s.Triangles) != tst.wantTris || s.TriangleCount != uint32(tst.wantTris) {
+				t.Errorf("got %d triangles, count %d; want %d", len(s.Triangles), s.TriangleCount, tst.wantTris)
+			}
+			if v := s.Volume(); !near(v, tst.wantVolume) {
+				t.Errorf("got volume %v; want %v", v, tst.wantVolume)
+			}
+			if bad := s.BadNormals(1e-6); len(bad) > 0 {
+				t.Errorf("got bad normals %v", bad)
+			}
+			if rep := s.Validate(0); tst.want.HolesLeft == 0 && !rep.OK() {
+				t.Errorf("got invalid repair %+v", rep)
+			}
+
+			// Colors are still read the same way, and filled triangles have the default
+			if s.ColorFormat != tst.in.ColorFormat || s.Color != tst.in.Color || s.Material != tst.in.Material {
+				t.Errorf("got colors %s %v %v; want %s %v %v", s.ColorFormat, s.Color, s.Material, tst.in.ColorFormat, tst.in.Color, tst.in.Material)
+			}
+			if tst.in.ColorFormat != ColorNone {
+				for i := range s.Triangles {
+					want := *tst.in.Color
+					if s.Triangles[i].Vertices == tst.in.Triangles[0].Vertices {
+						want = Color{R: 255, A: 255}
+					}
+					if c, ok := s.FacetColor(i); !ok || c != want {
+						t.Errorf("got triangle %d color %v, %t; want %v", i, c, ok, want)
+					}
+				}
+			}
+		})
+	}
+}
+func Test_triangulateLoop(t *testing.T) {
+	// An L shape, which fanning from the first vertex would fill outside its edges
+	verts := []Coordinate{{X: 2, Y: 1}, {X: 1, Y: 1}, {X: 1, Y: 2}, {Y: 2}, {}, {X: 2}}
+	loop := []int{0, 1, 2, 3, 4, 5}
+
+	tris := triangulateLoop(verts, loop)
+	if len(tris) != 4 {
+		t.Fatalf("got %d triangles; want 4", len(tris))
+	}
+
+	area := 0.0
+	for _, tri := range tris {
+		tr := Triangle{Vertices: [3]Coordinate{verts[tri[0]], verts[tri[1]], verts[tri[2]]}}
+		if n := tr.ComputeNormal(); n.Nk != 1 {
+			t.Errorf("got triangle %v facing %+v; want up", tri, n)
+		}
+		area += tr.Area()
+	}
+	if !near(area, 3) {
+		t.Errorf("got area %v; want 3", area)
+	}
+}
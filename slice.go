@@ -0,0 +1,290 @@
+package stl
+
+import "math"
+
+// Plane is the plane through Point facing along Normal
+type Plane struct {
+	Point  Vec3
+	Normal Vec3
+}
+
+// Axis is one of the X, Y and Z axes
+type Axis int
+
+// Axes
+const (
+	AxisX Axis = iota
+	AxisY
+	AxisZ
+)
+
+// String is the name of the Axis
+func (a Axis) String() string {
+	switch a {
+	case AxisX:
+		return "X"
+	case AxisY:
+		return "Y"
+	case AxisZ:
+		return "Z"
+	default:
+		return "unknown"
+	}
+}
+
+// Vec3 is the unit vector along the Axis
+func (a Axis) Vec3() Vec3 {
+	switch a {
+	case AxisX:
+		return Vec3{X: 1}
+	case AxisY:
+		return Vec3{Y: 1}
+	default:
+		return Vec3{Z: 1}
+	}
+}
+
+// Slice is the cross-section of a Solid in a Plane.
+// Points are given in the plane as distances along U and V from the origin, so
+// slicing along AxisZ gives the X and Y of each point.
+type Slice struct {
+	Plane Plane
+	// U and V are unit vectors in the plane, with U × V along the Normal
+	U, V Vec3
+	// Polygons run counter-clockwise around material and clockwise around holes,
+	// seen from the side the Normal faces, when the Solid faces outward.
+	// They only have a point where the outline turns.
+	Polygons []Polygon
+	// Open are paths that did not close because the Solid has holes
+	Open [][]Vec2
+}
+
+// Regions pairs each outer boundary of the Slice with the holes inside it
+func (sl Slice) Regions() []Region {
+	return Regions(sl.Polygons)
+}
+
+// Point is the point in space at q in the Slice
+func (sl Slice) Point(q Vec2) Vec3 {
+	n := sl.Plane.Normal.Normalize()
+	return sl.U.Scale(q.X).Add(sl.V.Scale(q.Y)).Add(n.Scale(n.Dot(sl.Plane.Point)))
+}
+
+// Slice intersects the Solid with a Plane and joins the pieces into Polygons.
+// Vertices lying exactly on the plane are treated as just above it, so each one
+// is counted once and the Polygons still close, and Triangles lying in the plane
+// add nothing.  The Solid should be closed and manifold, as Repair leaves it.
+func (s *Solid) Slice(p Plane) Slice {
+	mesh, _ := s.Indexed(0)
+	faces := make([]int, len(mesh.Faces))
+	for i := range faces {
+		faces[i] = i
+	}
+
+	return sliceMesh(mesh, faces, p)
+}
+
+// SliceLayers slices the Solid at every layerHeight along axis, through the middle
+// of each layer from the bottom of the BoundingBox.  A last layer the Solid does not
+// reach halfway through is left out.  A layerHeight of zero or less gives no Slices.
+// See Solid.Slice.
+func (s *Solid) SliceLayers(axis Axis, layerHeight float64) []Slice {
+	if layerHeight <= 0 || len(s.Triangles) == 0 {
+		return nil
+	}
+
+	mesh, _ := s.Indexed(0)
+	n := axis.Vec3()
+	box := s.BoundingBox()
+	lo, hi := n.Dot(box.Min), n.Dot(box.Max)
+	layers := max(0, int(math.Ceil((hi-lo)/layerHeight-0.5)))
+
+	// Only give each layer the faces that span it
+	faces := make([][]int, layers)
+	for i, f := range mesh.Faces {
+		fmin, fmax := math.Inf(1), math.Inf(-1)
+		for _, v := range f.Vertices {
+			d := n.Dot(mesh.Vertices[v].Vec3())
+			fmin, fmax = min(fmin, d), max(fmax, d)
+		}
+
+		first := max(0, int(math.Ceil((fmin-lo)/layerHeight-0.5)))
+		last := min(layers-1, int(math.Floor((fmax-lo)/layerHeight-0.5)))
+		for l := first; l <= last; l++ {
+			faces[l] = append(faces[l], i)
+		}
+	}
+
+	slices := make([]Slice, layers)
+	for l := range slices {
+		h := lo + (float64(l)+0.5)*layerHeight
+		slices[l] = sliceMesh(mesh, faces[l], Plane{Point: n.Scale(h), Normal: n})
+	}
+
+	return slices
+}
+
+// sliceKey identifies where a slice crosses the edge between two vertices, lowest first
+type sliceKey [2]int
+
+// sliceSegment crosses a face from where the surface goes down through the plane to where it comes back up
+type sliceSegment struct {
+	from, to sliceKey
+}
+
+// sliceMesh slices the given faces of mesh with p
+func sliceMesh(mesh IndexedMesh, faces []int, p Plane) Slice {
+	n := p.Normal.Normalize()
+	sl := Slice{Plane: p}
+	sl.U, sl.V = sliceBasis(n)
+
+	offset := n.Dot(p.Point)
+	dist := make(map[int]float64)
+	distance := func(v int) float64 {
+		d, ok := dist[v]
+		if !ok {
+			d = n.Dot(mesh.Vertices[v].Vec3()) - offset
+			dist[v] = d
+		}
+		return d
+	}
+
+	// Where each edge crosses the plane, in the plane's coordinates
+	points := make(map[sliceKey]Vec2)
+	crossing := func(a, b int) sliceKey {
+		key := sliceKey{min(a, b), max(a, b)}
+		if _, ok := points[key]; !ok {
+			da, db := distance(key[0]), distance(key[1])
+			pa, pb := mesh.Vertices[key[0]].Vec3(), mesh.Vertices[key[1]].Vec3()
+			x := pa.Add(pb.Sub(pa).Scale(da / (da - db)))
+			points[key] = Vec2{x.Dot(sl.U), x.Dot(sl.V)}
+		}
+		return key
+	}
+
+	var segs []sliceSegment
+	for _, fi := range faces {
+		v := mesh.Faces[fi].Vertices
+		var seg sliceSegment
+		found := 0
+		for j := 0; j < 3; j++ {
+			a, b := v[j], v[(j+1)%3]
+			above, next := distance(a) >= 0, distance(b) >= 0
+			switch {
+			case above && !next:
+				seg.from = crossing(a, b)
+				found++
+			case !above && next:
+				seg.to = crossing(a, b)
+				found++
+			}
+		}
+		if found == 2 {
+			segs = append(segs, seg)
+		}
+	}
+
+	sl.Polygons, sl.Open = chainSegments(segs, points)
+	return sl
+}
+
+// chainSegments joins segments end to start into closed Polygons, and open paths where they do not meet
+func chainSegments(segs []sliceSegment, points map[sliceKey]Vec2) ([]Polygon, [][]Vec2) {
+	starting := make(map[sliceKey][]int, len(segs))
+	ending := make(map[sliceKey]int, len(segs))
+	for i, s := range segs {
+		starting[s.from] = append(starting[s.from], i)
+		ending[s.to]++
+	}
+
+	used := make([]bool, len(segs))
+	follow := func(i int) ([]Vec2, bool) {
+		first := segs[i].from
+		path := []Vec2{points[first]}
+		for {
+			used[i] = true
+			at := segs[i].to
+			if at == first {
+				return path, true
+			}
+			path = appendPoint(path, points[at])
+
+			i = -1
+			for _, j := range starting[at] {
+				if !used[j] {
+					i = j
+					break
+				}
+			}
+			if i < 0 {
+				return path, false
+			}
+		}
+	}
+
+	var polys []Polygon
+	var open [][]Vec2
+
+	// Paths that start where nothing ends are open, so follow them from their start
+	for i, s := range segs {
+		if !used[i] && ending[s.from] == 0 {
+			path, _ := follow(i)
+			open = append(open, path)
+		}
+	}
+	for i := range segs {
+		if used[i] {
+			continue
+		}
+
+		path, closed := follow(i)
+		if !closed {
+			open = append(open, path)
+			continue
+		}
+
+		// Vertices on the plane leave zero length edges behind
+		if len(path) > 1 && path[len(path)-1] == path[0] {
+			path = path[:len(path)-1]
+		}
+		// Each triangle crossed leaves a point, even along a straight side
+		if poly := dropCollinear(path); len(poly) >= 3 && poly.Area() != 0 {
+			polys = append(polys, poly)
+		}
+	}
+
+	return polys, open
+}
+func dropCollinear(path []Vec2) Polygon {
+	poly := make(Polygon, 0, len(path))
+	for i, p := range path {
+		a := p.Sub(path[(i+len(path)-1)%len(path)])
+		b := path[(i+1)%len(path)].Sub(p)
+		if math.Abs(a.Cross(b)) > 1e-9*a.Len()*b.Len() {
+			poly = append(poly, p)
+		}
+	}
+
+	return poly
+}
+func appendPoint(path []Vec2, p Vec2) []Vec2 {
+	if path[len(path)-1] == p {
+		return path
+	}
+
+	return append(path, p)
+}
+
+// sliceBasis is U and V for a plane with unit normal n, keeping the axes for planes facing along one
+func sliceBasis(n Vec3) (Vec3, Vec3) {
+	switch n {
+	case Vec3{X: 1}:
+		return Vec3{Y: 1}, Vec3{Z: 1}
+	case Vec3{Y: 1}:
+		return Vec3{Z: 1}, Vec3{X: 1}
+	case Vec3{Z: 1}:
+		return Vec3{X: 1}, Vec3{Y: 1}
+	}
+
+	return planeBasis(n)
+}
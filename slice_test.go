@@ -0,0 +1,179 @@
+package stl
+
+import (
+	"math"
+	"testing"
+)
+
+// octahedron has a vertex at 1 and -1 along each axis
+func octahedron() Solid {
+	px, nx := Coordinate{X: 1}, Coordinate{X: -1}
+	py, ny := Coordinate{Y: 1}, Coordinate{Y: -1}
+	pz, nz := Coordinate{Z: 1}, Coordinate{Z: -1}
+
+	s := Solid{Triangles: []Triangle{
+		{Vertices: [3]Coordinate{px, py, pz}},
+		{Vertices: [3]Coordinate{py, nx, pz}},
+		{Vertices: [3]Coordinate{nx, ny, pz}},
+		{Vertices: [3]Coordinate{ny, px, pz}},
+		{Vertices: [3]Coordinate{py, px, nz}},
+		{Vertices: [3]Coordinate{nx, py, nz}},
+		{Vertices: [3]Coordinate{ny, nx, nz}},
+		{Vertices: [3]Coordinate{px, ny, nz}},
+	}}
+	s.RecomputeNormals()
+	s.TriangleCount = uint32(len(s.Triangles))
+
+	return s
+}
+func TestSolid_Slice(t *testing.T) {
+	open := testBox(1, 2, 3)
+	open.Triangles = append(open.Triangles[:10], open.Triangles[11:]...)
+
+	for _, tst := range []struct {
+		name      string
+		in        Solid
+		plane     Plane
+		wantAreas []float64
+		wantOpen  int
+	}{
+		{
+			name:      "unit cube",
+			in:        testBox(1, 1, 1),
+			plane:     Plane{Point: Vec3{Z: 0.5}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{1},
+		},
+		{
+			name:      "box",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{Z: 1.5}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{2},
+		},
+		{
+			name:      "box seen from below",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{Z: 1.5}, Normal: Vec3{Z: -1}},
+			wantAreas: []float64{2},
+		},
+		{
+			name:      "box across",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{X: 0.5}, Normal: Vec3{X: 1}},
+			wantAreas: []float64{6},
+		},
+		{
+			name:      "box on a slant",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{X: 0.5, Y: 1, Z: 1.5}, Normal: Vec3{X: 1, Y: 1}},
+			wantAreas: []float64{3 * math.Sqrt2},
+		},
+		{
+			name:      "box bottom",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Normal: Vec3{Z: 1}},
+			wantAreas: []float64{},
+		},
+		{
+			name:      "box top",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{Z: 3}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{2},
+		},
+		{
+			name:      "missing the plane",
+			in:        testBox(1, 2, 3),
+			plane:     Plane{Point: Vec3{Z: 5}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{},
+		},
+		{
+			name:      "through vertices",
+			in:        octahedron(),
+			plane:     Plane{Normal: Vec3{Z: 1}},
+			wantAreas: []float64{2},
+		},
+		{
+			name:      "void",
+			in:        boxWithVoid(false),
+			plane:     Plane{Point: Vec3{Z: 2}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{16, -4},
+		},
+		{
+			name:      "open",
+			in:        open,
+			plane:     Plane{Point: Vec3{Z: 1.5}, Normal: Vec3{Z: 1}},
+			wantAreas: []float64{},
+			wantOpen:  1,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			sl := tst.in.Slice(tst.plane)
+			if len(sl.Polygons) != len(tst.wantAreas) {
+				t.Fatalf("got %d polygons; want %d", len(sl.Polygons), len(tst.wantAreas))
+			}
+			for i, p := range sl.Polygons {
+				if !near(p.Area(), tst.wantAreas[i]) {
+					t.Errorf("got polygon %d area %v; want %v", i, p.Area(), tst.wantAreas[i])
+				}
+				// Only the corners are left of each rectangle
+				if len(p) != 4 {
+					t.Errorf("got polygon %d with %d points; want 4", i, len(p))
+				}
+
+				// Every point maps back onto the plane
+				for _, q := range p {
+					if d := sl.Point(q).Sub(tst.plane.Point).Dot(tst.plane.Normal.Normalize()); !near(d+1, 1) {
+						t.Errorf("got point %v off the plane by %v", q, d)
+					}
+				}
+			}
+			if len(sl.Open) != tst.wantOpen {
+				t.Errorf("got %d open paths; want %d", len(sl.Open), tst.wantOpen)
+			}
+		})
+	}
+}
+func TestSolid_SliceLayers(t *testing.T) {
+	s := testBox(1, 2, 3)
+
+	for _, tst := range []struct {
+		axis   Axis
+		height float64
+		want   []float64
+	}{
+		{axis: AxisZ, height: 1, want: []float64{2, 2, 2}},
+		{axis: AxisX, height: 0.3, want: []float64{6, 6, 6}},
+		{axis: AxisY, height: 1.5, want: []float64{3}},
+		{axis: AxisY, height: 5},
+		{axis: AxisZ, height: 0},
+	} {
+		layers := s.SliceLayers(tst.axis, tst.height)
+		if len(layers) != len(tst.want) {
+			t.Fatalf("got %d %s layers of %v; want %d", len(layers), tst.axis, tst.height, len(tst.want))
+		}
+		for i, l := range layers {
+			if len(l.Polygons) != 1 || !near(l.Polygons[0].Area(), tst.want[i]) {
+				t.Errorf("got %s layer %d polygons %v; want area %v", tst.axis, i, l.Polygons, tst.want[i])
+			}
+		}
+	}
+
+	// Layers through the middle of each layer, from the bottom
+	layers := s.SliceLayers(AxisZ, 1)
+	for i, l := range layers {
+		if want := float64(i) + 0.5; !near(l.Plane.Point.Z, want) {
+			t.Errorf("got layer %d at %v; want %v", i, l.Plane.Point.Z, want)
+		}
+	}
+
+	// Slicing along Z keeps X and Y
+	for _, q := range layers[0].Polygons[0] {
+		if q.X < 0 || q.X > 1 || q.Y < 0 || q.Y > 2 {
+			t.Errorf("got point %v outside the box", q)
+		}
+	}
+	if r := layers[0].Regions(); len(r) != 1 {
+		t.Errorf("got %d regions; want 1", len(r))
+	}
+}
@@ -1,53 +1,112 @@
 package stl
 
 import (
+	"bufio"
 	"bytes"
 	"fmt"
-	"math"
 )
 
-func splitTrianglesASCII(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	// End of input
-	if atEOF && len(data) == 0 {
+// splitFacetsASCII returns a split func for chunks of about chunkSize bytes that end just after an "endfacet" keyword.
+// Whatever is left at the end of the input, including "endsolid", is returned as the final chunk.
+// Chunks are split on keywords rather than lines, so any layout of whitespace is allowed.
+func splitFacetsASCII(chunkSize int) bufio.SplitFunc {
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		// Request more data
+		if !atEOF && len(data) < chunkSize {
+			return 0, nil, nil
+		}
+
+		// Return the next chunk
+		if end := facetBoundary(data, chunkSize); end > 0 {
+			return end, data[:end], nil
+		}
+
+		// Final chunk, which may be empty
+		if atEOF {
+			return len(data), data, bufio.ErrFinalToken
+		}
+
+		// Request more data
 		return 0, nil, nil
 	}
+}
 
-	// Find the data before the 7th newline
-	for n := 0; n < 7; n++ {
-		idx := bytes.IndexByte(data[advance+1:], '\n')
-		if idx < 0 {
-			// Invalid input
-			if atEOF && (len(data) < 8 || string(data[:8]) != "endsolid") {
-				return 0, nil, fmt.Errorf("invalid input data")
-			}
+// facetBoundary is the index just after the last complete "endfacet" at or before limit.
+// If there is none, it is the index after the first one past limit, or 0 if there are none.
+// The keyword must be followed by whitespace so one cut off at the end of data is not matched.
+func facetBoundary(data []byte, limit int) int {
+	const kw = "endfacet"
 
-			// Request more data
-			return 0, nil, nil
+	for i := min(limit, len(data)) - len(kw); i >= 0; i-- {
+		if i+len(kw) < len(data) && isKeywordAt(data, i, kw) {
+			return i + len(kw)
+		}
+	}
+	for i := max(min(limit, len(data))-len(kw)+1, 0); i+len(kw) < len(data); i++ {
+		if isKeywordAt(data, i, kw) {
+			return i + len(kw)
 		}
-		advance += idx + 1
 	}
 
-	// Made it to the end of a token
-	return advance + 1, data[:advance], nil
+	return 0
 }
-func splitTrianglesBinary(data []byte, atEOF bool) (advance int, token []byte, err error) {
-	const chunkSize = 50000
 
-	// Return the next chunk, or ask for more data
-	if len(data) >= chunkSize {
-		return chunkSize, data[:chunkSize], nil
+// isKeywordAt reports whether kw is at data[i] as a whole word, ignoring case
+func isKeywordAt(data []byte, i int, kw string) bool {
+	end := i + len(kw)
+	switch {
+	case end > len(data), data[i]|0x20 != kw[0]:
+		return false
+	case i > 0 && !isSpace(data[i-1]), end < len(data) && !isSpace(data[end]):
+		return false
 	}
 
-	// Invalid data
-	if atEOF && math.Mod(float64(len(data)), 50) != 0 {
-		return 0, nil, fmt.Errorf("invalid input data")
-	}
+	return bytes.EqualFold(data[i:end], []byte(kw))
+}
 
-	// Last chunk of data
-	if atEOF && len(data) > 0 {
-		return len(data), data, nil
+// countKeyword is the number of times kw is in data as a whole word, ignoring case
+func countKeyword(data []byte, kw string) int {
+	cnt := 0
+	for i := 0; i+len(kw) <= len(data); i++ {
+		if isKeywordAt(data, i, kw) {
+			cnt++
+			i += len(kw)
+		}
 	}
 
-	// Request more data
-	return 0, nil, nil
+	return cnt
+}
+
+// splitTrianglesBinary returns a split func for chunks of chunkSize bytes, rounded down to whole 50 byte triangles.
+// When lenient, a partial triangle at the end of the input is dropped rather than being an error.
+func splitTrianglesBinary(chunkSize int, lenient bool) bufio.SplitFunc {
+	chunkSize = max(chunkSize-chunkSize%50, 50)
+
+	return func(data []byte, atEOF bool) (advance int, token []byte, err error) {
+		// Return the next chunk, or ask for more data
+		if len(data) >= chunkSize {
+			return chunkSize, data[:chunkSize], nil
+		}
+		if !atEOF {
+			return 0, nil, nil
+		}
+
+		// Last chunk of data, up to any partial triangle
+		if whole := len(data) - len(data)%50; whole > 0 {
+			return whole, data[:whole], nil
+		}
+
+		// Drop a partial triangle at the end of the input
+		if len(data) > 0 && lenient {
+			return 0, nil, bufio.ErrFinalToken
+		}
+
+		// Invalid data
+		if len(data) > 0 {
+			return 0, nil, fmt.Errorf("%w: partial triangle of %d bytes", ErrTruncated, len(data))
+		}
+
+		// End of input
+		return 0, nil, nil
+	}
 }
@@ -3,131 +3,245 @@ package stl
 import (
 	"bufio"
 	"bytes"
-	"fmt"
 	"testing"
 )
 
-func Test_splitTrianglesASCII(t *testing.T) {
+func Test_splitFacetsASCII(t *testing.T) {
+	facet := "facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet"
 	for _, tst := range []struct {
-		in   []byte
-		eof  bool
-		want struct {
+		name      string
+		chunkSize int
+		in        []byte
+		eof       bool
+		want      struct {
 			advance int
 			token   []byte
 			err     error
 		}
 	}{
-		// Empty input and EOF
 		{
-			in:  []byte{},
-			eof: true,
+			name:      "empty input and EOF",
+			chunkSize: 1,
+			in:        []byte{},
+			eof:       true,
 			want: struct {
 				advance int
 				token   []byte
 				err     error
 			}{
 				advance: 0,
-				token:   nil,
-				err:     nil,
+				token:   []byte{},
+				err:     bufio.ErrFinalToken,
 			},
 		},
-		// Full token found
 		{
-			in:  []byte("facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet\n"),
-			eof: false,
+			name:      "full token found",
+			chunkSize: 1,
+			in:        []byte(facet + "\n"),
 			want: struct {
 				advance int
 				token   []byte
 				err     error
 			}{
-				advance: 136,
-				token:   []byte("facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet"),
-				err:     nil,
+				advance: 135,
+				token:   []byte(facet),
 			},
 		},
-		// Beginning of token
 		{
-			in:  []byte("facet normal 0.05082 -0.24321 -0.96864\n"),
-			eof: false,
+			name:      "beginning of token",
+			chunkSize: 1,
+			in:        []byte("facet normal 0.05082 -0.24321 -0.96864\n"),
+		},
+		{
+			name:      "endfacet may be the start of a longer word",
+			chunkSize: 1,
+			in:        []byte(facet),
+		},
+		{
+			name:      "full token found, but there is more in data",
+			chunkSize: 1,
+			in:        []byte(facet + "\n" + facet + "\n"),
 			want: struct {
 				advance int
 				token   []byte
 				err     error
 			}{
-				advance: 0,
-				token:   nil,
-				err:     nil,
+				advance: 135,
+				token:   []byte(facet),
 			},
 		},
-		// Full token found, but there is more in data
 		{
-			in:  []byte("facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet\nfacet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet\n"),
-			eof: false,
+			name:      "chunk holds as many facets as fit",
+			chunkSize: 300,
+			in:        []byte(facet + "\n" + facet + "\n" + facet + "\n"),
 			want: struct {
 				advance int
 				token   []byte
 				err     error
 			}{
-				advance: 136,
-				token:   []byte("facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet"),
-				err:     nil,
+				advance: 271,
+				token:   []byte(facet + "\n" + facet),
 			},
 		},
-		// End of input, ignoring "endsolid"
 		{
-			in:  []byte("endsolid ASCII_STL_of_a_sphericon_by_CMG_Lee\n"),
-			eof: true,
+			name:      "more data is requested until the chunk is full",
+			chunkSize: 1000,
+			in:        []byte(facet + "\n"),
+		},
+		{
+			name:      "upper case and CRLF",
+			chunkSize: 1,
+			in:        []byte("FACET NORMAL 0 0 1 OUTER LOOP VERTEX 0 0 0 VERTEX 1 0 0 VERTEX 0 1 0 ENDLOOP ENDFACET\r\nFACET"),
 			want: struct {
 				advance int
 				token   []byte
 				err     error
 			}{
-				advance: 0,
-				token:   nil,
-				err:     nil,
+				advance: 85,
+				token:   []byte("FACET NORMAL 0 0 1 OUTER LOOP VERTEX 0 0 0 VERTEX 1 0 0 VERTEX 0 1 0 ENDLOOP ENDFACET"),
+			},
+		},
+		{
+			name:      "end of input is the final token",
+			chunkSize: 1,
+			in:        []byte("endsolid ASCII_STL_of_a_sphericon_by_CMG_Lee\n"),
+			eof:       true,
+			want: struct {
+				advance int
+				token   []byte
+				err     error
+			}{
+				advance: 45,
+				token:   []byte("endsolid ASCII_STL_of_a_sphericon_by_CMG_Lee\n"),
+				err:     bufio.ErrFinalToken,
 			},
 		},
 	} {
 		tst := tst
-		t.Run(fmt.Sprintf("splitTrianglesASCII - %q", tst.in), func(t *testing.T) {
+		t.Run(tst.name, func(t *testing.T) {
 			t.Parallel()
-			gotAdvance, gotToken, gotError := splitTrianglesASCII(tst.in, tst.eof)
+			gotAdvance, gotToken, gotError := splitFacetsASCII(tst.chunkSize)(tst.in, tst.eof)
 			if gotAdvance != tst.want.advance {
 				t.Errorf("got %d; want %d", gotAdvance, tst.want.advance)
 			}
-			if !bytes.Equal(gotToken, tst.want.token) {
-				t.Errorf("got \n%q\nwant\n%q", string(gotToken), string(tst.want.token))
-			}
-			if gotError == nil && tst.want.err != nil || gotError != nil && tst.want.err == nil {
-				t.Errorf("unexpected nil")
-				t.FailNow()
+			if !bytes.Equal(gotToken, tst.want.token) || (gotToken == nil) != (tst.want.token == nil) {
+				t.Errorf("got \n%q\nwant\n%q", gotToken, tst.want.token)
 			}
-			if gotError != nil && tst.want.err != nil && gotError.Error() != tst.want.err.Error() {
-				t.Errorf("got %s; want %s", gotError.Error(), tst.want.err.Error())
+			if gotError != tst.want.err {
+				t.Errorf("got %v; want %v", gotError, tst.want.err)
 			}
 		})
 	}
 }
-func Test_splitTrianglesScannerASCII(t *testing.T) {
+func Test_splitFacetsScannerASCII(t *testing.T) {
 	data := "solid ASCII_STL_of_a_sphericon_by_CMG_Lee\n facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet\n facet normal -0.05382 -0.80723 0.58777\n  outer loop\n   vertex 0 -1000 0\n   vertex 995 0 105\n   vertex 988 0 156\n  endloop\n endfacet\n facet normal -0.06315 -0.82099 0.56743\n  outer loop\n   vertex 0 -1000 0\n   vertex 999 0 52\n   vertex 995 0 105\n  endloop\n endfacet\nendsolid ASCII_STL_of_a_sphericon_by_CMG_Lee\n"
 	tokens := []string{
 		" facet normal 0.05082 -0.24321 -0.96864\n  outer loop\n   vertex -1000 0 0\n   vertex 0 -358 -934\n   vertex 0 -407 -914\n  endloop\n endfacet",
-		" facet normal -0.05382 -0.80723 0.58777\n  outer loop\n   vertex 0 -1000 0\n   vertex 995 0 105\n   vertex 988 0 156\n  endloop\n endfacet",
-		" facet normal -0.06315 -0.82099 0.56743\n  outer loop\n   vertex 0 -1000 0\n   vertex 999 0 52\n   vertex 995 0 105\n  endloop\n endfacet",
+		"\n facet normal -0.05382 -0.80723 0.58777\n  outer loop\n   vertex 0 -1000 0\n   vertex 995 0 105\n   vertex 988 0 156\n  endloop\n endfacet",
+		"\n facet normal -0.06315 -0.82099 0.56743\n  outer loop\n   vertex 0 -1000 0\n   vertex 999 0 52\n   vertex 995 0 105\n  endloop\n endfacet",
+		"\nendsolid ASCII_STL_of_a_sphericon_by_CMG_Lee\n",
 	}
 
 	// Create a buffered reader to get past the first line, which is the header
 	buf := bufio.NewReader(bytes.NewReader([]byte(data)))
 	_, _, _ = buf.ReadLine()
 
-	// Create a scanner that takes in the splitTriangles split func
+	// Create a scanner that takes one facet at a time
 	scanner := bufio.NewScanner(buf)
-	scanner.Split(splitTrianglesASCII)
+	scanner.Split(splitFacetsASCII(1))
 
 	// Check that tokens are taken out in order
-	for i := 0; scanner.Scan(); i++ {
+	i := 0
+	for ; scanner.Scan(); i++ {
+		if i >= len(tokens) {
+			t.Fatalf("got extra token %q", scanner.Text())
+		}
 		if scanner.Text() != tokens[i] {
 			t.Errorf("got %q\n; want %q\n", scanner.Text(), tokens[i])
 		}
 	}
+	if i != len(tokens) {
+		t.Errorf("got %d tokens; want %d", i, len(tokens))
+	}
+}
+func Test_splitTrianglesBinary(t *testing.T) {
+	for _, tst := range []struct {
+		name      string
+		chunkSize int
+		lenient   bool
+		in        []byte
+		eof       bool
+		advance   int
+		wantErr   bool
+	}{
+		{
+			name:      "full chunk",
+			chunkSize: 100,
+			in:        make([]byte, 120),
+			advance:   100,
+		},
+		{
+			name:      "chunk size rounded down",
+			chunkSize: 149,
+			in:        make([]byte, 160),
+			advance:   100,
+		},
+		{
+			name:      "request more data",
+			chunkSize: 100,
+			in:        make([]byte, 60),
+			advance:   0,
+		},
+		{
+			name:      "last chunk",
+			chunkSize: 100,
+			in:        make([]byte, 50),
+			eof:       true,
+			advance:   50,
+		},
+		{
+			name:      "last chunk before partial triangle",
+			chunkSize: 100,
+			in:        make([]byte, 60),
+			eof:       true,
+			advance:   50,
+		},
+		{
+			name:      "partial triangle",
+			chunkSize: 100,
+			in:        make([]byte, 10),
+			eof:       true,
+			wantErr:   true,
+		},
+		{
+			name:      "partial triangle, lenient final token",
+			chunkSize: 100,
+			lenient:   true,
+			in:        make([]byte, 10),
+			eof:       true,
+			wantErr:   true,
+		},
+		{
+			name:      "end of input",
+			chunkSize: 100,
+			in:        []byte{},
+			eof:       true,
+			advance:   0,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			gotAdvance, gotToken, gotError := splitTrianglesBinary(tst.chunkSize, tst.lenient)(tst.in, tst.eof)
+			if (gotError != nil) != tst.wantErr {
+				t.Fatalf("got %v; want error %t", gotError, tst.wantErr)
+			}
+			if gotAdvance != tst.advance {
+				t.Errorf("got %d; want %d", gotAdvance, tst.advance)
+			}
+			if len(gotToken) != tst.advance {
+				t.Errorf("got token of %d bytes; want %d", len(gotToken), tst.advance)
+			}
+		})
+	}
 }
@@ -1,12 +1,5 @@
 package stl
 
-import (
-	"runtime"
-)
-
-// Number of worker goroutines
-var concurrencyLevel = runtime.NumCPU()
-
 // Coordinate is the X, Y, and Z of a Triangle Vertex
 type Coordinate struct {
 	X float32
@@ -23,7 +16,8 @@ type UnitVector struct {
 
 // Triangle contains 3 vertices, a normal, and an attribute byte count
 // AttrByteCnt does not get recorded for ASCII type
-// It is for binary only, and does not have a standard use
+// It is for binary only, and does not have a standard use, though it often holds a color.
+// See Triangle.Color
 type Triangle struct {
 	Normal      UnitVector
 	Vertices    [3]Coordinate
@@ -32,7 +26,48 @@ type Triangle struct {
 
 // Solid is an STL object
 type Solid struct {
-	Header        string
+	Header string
+	// TriangleCount is the number of Triangles read.  Writers use len(Triangles) instead.
 	TriangleCount uint32
 	Triangles     []Triangle
+	// ColorFormat is the convention for colors in the Triangles of binary input
+	ColorFormat ColorFormat
+	// Color and Material are the Materialise Magics defaults from a binary header, or nil
+	Color    *Color
+	Material *Material
+}
+
+// Format is the encoding of an STL file
+type Format int
+
+const (
+	// FormatUnknown is an undetermined format
+	FormatUnknown Format = iota
+	// FormatASCII is the human readable "solid ... endsolid" format
+	FormatASCII
+	// FormatBinary is the 80 byte header, triangle count, and 50 byte triangle format
+	FormatBinary
+	// FormatOBJ is Wavefront OBJ, read with FromOBJ
+	FormatOBJ
+	// FormatPLY is the Polygon File Format, read with FromPLY
+	FormatPLY
+	// Format3MF is the 3D Manufacturing Format, read with From3MF
+	Format3MF
+)
+
+func (f Format) String() string {
+	switch f {
+	case FormatASCII:
+		return "ASCII"
+	case FormatBinary:
+		return "binary"
+	case FormatOBJ:
+		return "OBJ"
+	case FormatPLY:
+		return "PLY"
+	case Format3MF:
+		return "3MF"
+	default:
+		return "unknown"
+	}
 }
@@ -2,9 +2,11 @@ package stl
 
 import (
 	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
 	"io"
 	"os"
-	"sort"
 	"testing"
 
 	"gitlab.com/russoj88/stl"
@@ -46,8 +48,6 @@ func TestToASCIIFile(t *testing.T) {
 		t.Errorf("could not read stl: %v", err)
 	}
 
-	orderTriangles(&solid)
-
 	// Write solid to a buffer
 	err = solid.ToASCIIFile(dumpFile)
 	if err != nil {
@@ -80,26 +80,61 @@ func TestToBinaryFile(t *testing.T) {
 		t.Errorf("could not read stl: %v", err)
 	}
 
-	orderTriangles(&solid)
-
 	// Write solid to a buffer
 	err = solid.ToBinaryFile(dumpFile)
 	if err != nil {
 		t.Errorf("failed to write file: %v", err)
 	}
 
-	// Confirm the buffer matches golden file
-	gFile, err := os.Open(goldenFile)
+	// Confirm the dump matches the golden file, apart from the count written from the triangles
+	golden, err := os.ReadFile(goldenFile)
 	if err != nil {
-		t.Fatalf("could not open golden file %s", goldenFile)
+		t.Fatalf("could not read golden file %s", goldenFile)
 	}
-	dFile, err := os.Open(dumpFile)
+	dump, err := os.ReadFile(dumpFile)
 	if err != nil {
-		t.Fatalf("could not open dump file %s", dumpFile)
+		t.Fatalf("could not read dump file %s", dumpFile)
 	}
-	if !contentsAreEqual(gFile, dFile) {
+	if len(dump) != len(golden) || !bytes.Equal(dump[:80], golden[:80]) || !bytes.Equal(dump[84:], golden[84:]) {
 		t.Errorf("got dump_binary.stl; want small_binary.stl")
 	}
+	if count := binary.LittleEndian.Uint32(dump[80:]); count != 3 {
+		t.Errorf("got count %d; want 3", count)
+	}
+}
+func TestFromFile_CountMismatch(t *testing.T) {
+	t.Parallel()
+	testFile := "testdata/small_binary.stl"
+
+	// By default the triangles present are read and the count is only a warning
+	var warnings []error
+	solid, err := stl.FromFileContext(context.Background(), testFile, stl.ReadOptions{
+		OnWarning: func(err error) { warnings = append(warnings, err) },
+	})
+	if err != nil {
+		t.Fatalf("could not read stl: %v", err)
+	}
+	if len(solid.Triangles) != 3 || solid.TriangleCount != 9438 {
+		t.Errorf("got %d triangles, count %d; want 3, count 9438", len(solid.Triangles), solid.TriangleCount)
+	}
+	if len(warnings) != 1 || !errors.Is(warnings[0], stl.ErrCountMismatch) {
+		t.Errorf("got warnings %v; want one %v", warnings, stl.ErrCountMismatch)
+	}
+
+	// StrictCount makes it an error
+	_, err = stl.FromFileContext(context.Background(), testFile, stl.ReadOptions{StrictCount: true})
+	if !errors.Is(err, stl.ErrCountMismatch) {
+		t.Errorf("got %v; want %v", err, stl.ErrCountMismatch)
+	}
+
+	// Lenient trusts the data over the count
+	solid, err = stl.FromFileContext(context.Background(), testFile, stl.ReadOptions{Lenient: true, StrictCount: true})
+	if err != nil {
+		t.Fatalf("could not read stl: %v", err)
+	}
+	if len(solid.Triangles) != 3 || solid.TriangleCount != 3 {
+		t.Errorf("got %d triangles, count %d; want 3", len(solid.Triangles), solid.TriangleCount)
+	}
 }
 func TestFrom_Binary(t *testing.T) {
 	t.Parallel()
@@ -134,8 +169,12 @@ func TestFrom_BinaryError(t *testing.T) {
 	testFile := "testdata/invalid_binary.stl"
 
 	// Read into Solid type
-	if _, err := stl.FromFile(testFile); err == nil {
-		t.Errorf("got no error; want an error")
+	_, err := stl.FromFile(testFile)
+	if err == nil {
+		t.Fatalf("got no error; want an error")
+	}
+	if !errors.Is(err, stl.ErrTruncated) {
+		t.Errorf("got %v; want %v", err, stl.ErrTruncated)
 	}
 }
 func TestFrom_ASCII(t *testing.T) {
@@ -171,8 +210,18 @@ func TestFrom_ASCIIErrorTriangle(t *testing.T) {
 	testFile := "testdata/invalid_ASCII_triangle.stl"
 
 	// Read into Solid type
-	if _, err := stl.FromFile(testFile); err == nil {
-		t.Errorf("got no error; want an error")
+	_, err := stl.FromFile(testFile)
+	if err == nil {
+		t.Fatalf("got no error; want an error")
+	}
+
+	// The second facet normal is missing a value
+	var pErr *stl.ParseError
+	if !errors.As(err, &pErr) {
+		t.Fatalf("got %v; want a ParseError", err)
+	}
+	if pErr.Line != 9 || pErr.Facet != 1 || pErr.Offset != 176 {
+		t.Errorf("got line %d, facet %d, byte %d; want line 9, facet 1, byte 176", pErr.Line, pErr.Facet, pErr.Offset)
 	}
 }
 func TestFrom_ASCIIErrorLine(t *testing.T) {
@@ -184,29 +233,7 @@ func TestFrom_ASCIIErrorLine(t *testing.T) {
 		t.Errorf("got no error; want an error")
 	}
 }
-func orderTriangles(solid *stl.Solid) {
-	sort.Slice(solid.Triangles, func(i, j int) bool {
-		for idx := 0; idx < 3; idx++ {
-			l := solid.Triangles[i].Vertices[idx]
-			r := solid.Triangles[j].Vertices[idx]
-			if l.X == r.X {
-				if l.Y == r.Y {
-					if l.Z == r.Z {
-						continue
-					}
-					return l.Z < r.Z
-				}
-				return l.Y < r.Y
-			}
-			return l.X < r.X
-		}
-
-		return solid.Triangles[i].Normal.Ni < solid.Triangles[j].Normal.Ni
-	})
-}
 func writeToBuffer(solid stl.Solid, To func(io.Writer) error, t *testing.T) *bytes.Buffer {
-	orderTriangles(&solid)
-
 	// Write to a binary buffer
 	buffer := bytes.NewBuffer([]byte{})
 	if err := To(buffer); err != nil {
@@ -0,0 +1,30 @@
+package stl
+
+// Transform applies m to every Triangle of the Solid.
+// Normals are transformed by the inverse-transpose of m so they stay perpendicular
+// to their Triangles, and the winding of each Triangle is flipped when m mirrors so
+// that the Triangles still face outward.
+func (s *Solid) Transform(m Matrix) {
+	det := m.Determinant()
+
+	// The inverse-transpose is the cofactor matrix over the determinant.  Only the
+	// sign of the determinant matters once normals are normalized, and using the
+	// cofactor matrix keeps a flattening transform from dividing by zero.
+	nm := m.cofactor()
+	if det < 0 {
+		nm = nm.Mul(ScaleUniform(-1))
+	}
+
+	for i := range s.Triangles {
+		t := &s.Triangles[i]
+
+		t.Normal = nm.ApplyVector(t.Normal.Vec3()).Normalize().UnitVector()
+		for j := range t.Vertices {
+			t.Vertices[j] = m.Apply(t.Vertices[j].Vec3()).Coordinate()
+		}
+
+		if det < 0 {
+			t.Vertices[1], t.Vertices[2] = t.Vertices[2], t.Vertices[1]
+		}
+	}
+}
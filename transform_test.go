@@ -0,0 +1,56 @@
+package stl
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSolid_Transform(t *testing.T) {
+	for _, tst := range []struct {
+		name       string
+		m          Matrix
+		wantVolume float64
+	}{
+		{
+			name:       "rotate and translate",
+			m:          Translate(10, -5, 2).Mul(RotateEuler(0.5, -1, 2)),
+			wantVolume: 6,
+		},
+		{
+			name:       "non-uniform scale",
+			m:          Scale(2, 0.5, 3),
+			wantVolume: 18,
+		},
+		{
+			name:       "mirror",
+			m:          Mirror(Vec3{}, Vec3{X: 1, Y: 1}),
+			wantVolume: 6,
+		},
+		{
+			name:       "negative scale",
+			m:          Scale(-1, -1, -1),
+			wantVolume: 6,
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			s := testBox(1, 2, 3)
+			s.Transform(tst.m)
+
+			// Winding still faces outward
+			if got := s.Volume(); math.Abs(got-tst.wantVolume) > 1e-4 {
+				t.Errorf("got volume %g; want %g", got, tst.wantVolume)
+			}
+
+			// Normals agree with the winding
+			for i, tri := range s.Triangles {
+				a, b, c := tri.Vertices[0].Vec3(), tri.Vertices[1].Vec3(), tri.Vertices[2].Vec3()
+				want := b.Sub(a).Cross(c.Sub(a)).Normalize()
+				if got := tri.Normal.Vec3(); got.Sub(want).Len() > 1e-5 {
+					t.Errorf("got normal %+v for triangle %d; want %+v", got, i, want)
+				}
+			}
+		})
+	}
+}
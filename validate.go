@@ -0,0 +1,250 @@
+package stl
+
+import "sort"
+
+// ValidationReport lists the problems that make a Solid unprintable.
+// Every problem refers to Triangles by their index in Solid.Triangles.
+type ValidationReport struct {
+	// OpenEdges are used by only one Triangle, so the surface has a hole
+	OpenEdges []Edge
+	// NonManifoldEdges are shared by more than two Triangles
+	NonManifoldEdges []Edge
+	// NonManifoldVertices are where separate fans of Triangles meet at a single point
+	NonManifoldVertices []Vertex
+	// Misoriented are pairs of neighboring Triangles that wind their shared edge the
+	// same way, so one of them faces the wrong way
+	Misoriented [][2]int
+	// Degenerate are Triangles with no area
+	Degenerate []int
+	// Duplicates are pairs of Triangles with the same vertices.  The first is the earlier.
+	Duplicates [][2]int
+	// Closed is set when every edge is shared by exactly two Triangles
+	Closed bool
+	// Outward is set when the surface is closed, consistently oriented, and every shell
+	// encloses a positive volume, or a negative one for a void inside another shell,
+	// so the winding of every Triangle faces out of the material
+	Outward bool
+}
+
+// Edge is an edge between two vertices and the Triangles that use it
+type Edge struct {
+	A         Coordinate
+	B         Coordinate
+	Triangles []int
+}
+
+// Vertex is a vertex and the Triangles that use it
+type Vertex struct {
+	Vertex    Coordinate
+	Triangles []int
+}
+
+// OK reports whether no problems were found and the surface is closed and facing outward
+func (r *ValidationReport) OK() bool {
+	return r.Outward &&
+		len(r.OpenEdges) == 0 &&
+		len(r.NonManifoldEdges) == 0 &&
+		len(r.NonManifoldVertices) == 0 &&
+		len(r.Misoriented) == 0 &&
+		len(r.Degenerate) == 0 &&
+		len(r.Duplicates) == 0
+}
+
+// Validate checks the topology of the Solid.
+// Vertices within tolerance of each other are treated as the same, as in IndexedMesh.Weld.
+// Orientation is checked from the winding of the vertices.  Use BadNormals to check
+// the stored normals agree.
+func (s *Solid) Validate(tolerance float64) ValidationReport {
+	var r ValidationReport
+	mesh, _ := s.Indexed(tolerance)
+
+	// Triangles that collapse to a line or point, and repeats of an earlier
+	// Triangle, are left out of the edge checks
+	skip := collapsedFaces(mesh)
+	dups := make(map[[3]int]int, len(mesh.Faces))
+	for i, f := range mesh.Faces {
+		if skip[i] || s.Triangles[i].Area() == 0 {
+			r.Degenerate = append(r.Degenerate, i)
+			continue
+		}
+
+		key := sortedTriple(f.Vertices)
+		if first, ok := dups[key]; ok {
+			r.Duplicates = append(r.Duplicates, [2]int{first, i})
+			skip[i] = true
+			continue
+		}
+		dups[key] = i
+	}
+
+	edges := meshEdges(mesh, skip)
+	for _, e := range edges {
+		switch {
+		case len(e.uses) == 1:
+			r.OpenEdges = append(r.OpenEdges, e.edge(mesh))
+		case len(e.uses) > 2:
+			r.NonManifoldEdges = append(r.NonManifoldEdges, e.edge(mesh))
+		case e.uses[0].forward == e.uses[1].forward:
+			r.Misoriented = append(r.Misoriented, [2]int{e.uses[0].face, e.uses[1].face})
+		}
+	}
+
+	for v, fans := range vertexFans(mesh, skip) {
+		var tris []int
+		for _, fan := range fans {
+			tris = append(tris, fan...)
+		}
+		sort.Ints(tris)
+		r.NonManifoldVertices = append(r.NonManifoldVertices, Vertex{Vertex: mesh.Vertices[v], Triangles: tris})
+	}
+
+	// Map iteration is random, so order problems by the first Triangle involved
+	sort.Slice(r.OpenEdges, func(i, j int) bool { return lessInts(r.OpenEdges[i].Triangles, r.OpenEdges[j].Triangles) })
+	sort.Slice(r.NonManifoldEdges, func(i, j int) bool {
+		return lessInts(r.NonManifoldEdges[i].Triangles, r.NonManifoldEdges[j].Triangles)
+	})
+	sort.Slice(r.NonManifoldVertices, func(i, j int) bool {
+		return lessInts(r.NonManifoldVertices[i].Triangles, r.NonManifoldVertices[j].Triangles)
+	})
+	sort.Slice(r.Misoriented, func(i, j int) bool { return lessInts(r.Misoriented[i][:], r.Misoriented[j][:]) })
+
+	r.Closed = len(mesh.Faces) > 0 && len(r.OpenEdges) == 0 && len(r.NonManifoldEdges) == 0
+	r.Outward = r.Closed && len(r.Misoriented) == 0
+	for _, sh := range meshShells(mesh, edgeComponents(mesh, skip)) {
+		r.Outward = r.Outward && sh.Outward()
+	}
+
+	return r
+}
+
+// edgeUse is a face using an edge, and whether it winds from the lower to the higher vertex index
+type edgeUse struct {
+	face    int
+	forward bool
+}
+
+// meshEdge is an edge between two vertex indexes, lowest first, and the faces using it
+type meshEdge struct {
+	a, b int
+	uses []edgeUse
+}
+
+func (e meshEdge) edge(mesh IndexedMesh) Edge {
+	tris := make([]int, len(e.uses))
+	for i, u := range e.uses {
+		tris[i] = u.face
+	}
+	sort.Ints(tris)
+
+	return Edge{A: mesh.Vertices[e.a], B: mesh.Vertices[e.b], Triangles: tris}
+}
+
+// meshEdges finds every edge of the faces not skipped
+func meshEdges(mesh IndexedMesh, skip []bool) map[[2]int]*meshEdge {
+	edges := make(map[[2]int]*meshEdge, 3*len(mesh.Faces)/2)
+	for i, f := range mesh.Faces {
+		if skip[i] {
+			continue
+		}
+		for j := 0; j < 3; j++ {
+			a, b := f.Vertices[j], f.Vertices[(j+1)%3]
+			key := [2]int{min(a, b), max(a, b)}
+			e, ok := edges[key]
+			if !ok {
+				e = &meshEdge{a: key[0], b: key[1]}
+				edges[key] = e
+			}
+			e.uses = append(e.uses, edgeUse{face: i, forward: a < b})
+		}
+	}
+
+	return edges
+}
+
+// vertexFans groups the faces around each vertex into fans connected by shared edges,
+// and returns the vertices with more than one fan, which are not manifold.
+func vertexFans(mesh IndexedMesh, skip []bool) map[int][][]int {
+	around := make([][]int, len(mesh.Vertices))
+	for i, f := range mesh.Faces {
+		if skip[i] {
+			continue
+		}
+		for _, v := range f.Vertices {
+			around[v] = append(around[v], i)
+		}
+	}
+
+	fans := make(map[int][][]int)
+	for v, faces := range around {
+		if len(faces) < 2 {
+			continue
+		}
+
+		// Faces sharing another vertex share an edge through v
+		uf := newUnionFind(len(faces))
+		first := make(map[int]int)
+		for k, fi := range faces {
+			for _, w := range mesh.Faces[fi].Vertices {
+				if w == v {
+					continue
+				}
+				if j, ok := first[w]; ok {
+					uf.union(j, k)
+				} else {
+					first[w] = k
+				}
+			}
+		}
+
+		groups := make(map[int][]int)
+		for k, fi := range faces {
+			root := uf.find(k)
+			groups[root] = append(groups[root], fi)
+		}
+		if len(groups) > 1 {
+			for _, g := range groups {
+				fans[v] = append(fans[v], g)
+			}
+		}
+	}
+
+	return fans
+}
+
+// unionFind is a disjoint set of the integers up to its size
+type unionFind []int
+
+func newUnionFind(n int) unionFind {
+	uf := make(unionFind, n)
+	for i := range uf {
+		uf[i] = i
+	}
+
+	return uf
+}
+func (uf unionFind) find(i int) int {
+	for uf[i] != i {
+		uf[i] = uf[uf[i]]
+		i = uf[i]
+	}
+
+	return i
+}
+func (uf unionFind) union(i, j int) {
+	uf[uf.find(i)] = uf.find(j)
+}
+func sortedTriple(v [3]int) [3]int {
+	sort.Ints(v[:])
+	return v
+}
+
+// lessInts orders slices by their elements in turn
+func lessInts(a, b []int) bool {
+	for i := 0; i < len(a) && i < len(b); i++ {
+		if a[i] != b[i] {
+			return a[i] < b[i]
+		}
+	}
+
+	return len(a) < len(b)
+}
@@ -0,0 +1,155 @@
+package stl
+
+import "testing"
+
+func TestSolid_Validate(t *testing.T) {
+	box := func() Solid { return testBox(1, 2, 3) }
+
+	open := box()
+	open.Triangles = open.Triangles[1:]
+
+	flipped := box()
+	v := &flipped.Triangles[2].Vertices
+	v[1], v[2] = v[2], v[1]
+
+	insideOut := box()
+	for i := range insideOut.Triangles {
+		v := &insideOut.Triangles[i].Vertices
+		v[1], v[2] = v[2], v[1]
+	}
+
+	// A fin on the edge from the origin along X
+	fin := box()
+	fin.Triangles = append(fin.Triangles, Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 0.5, Y: -1, Z: -1}}})
+
+	// Two boxes touching at a corner
+	corner := box()
+	other := box()
+	other.Transform(Translate(1, 2, 3))
+	corner.Triangles = append(corner.Triangles, other.Triangles...)
+
+	duplicate := box()
+	duplicate.Triangles = append(duplicate.Triangles, duplicate.Triangles[4])
+
+	degenerate := box()
+	degenerate.Triangles = append(degenerate.Triangles, Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 1}}})
+
+	for _, tst := range []struct {
+		name                    string
+		in                      Solid
+		wantOK                  bool
+		wantClosed, wantOutward bool
+		wantOpen, wantNonMan    int
+		wantVertices            int
+		wantMisoriented         [][2]int
+		wantDegenerate          []int
+		wantDuplicates          [][2]int
+	}{
+		{
+			name:        "closed box",
+			in:          box(),
+			wantOK:      true,
+			wantClosed:  true,
+			wantOutward: true,
+		},
+		{
+			name:     "missing triangle",
+			in:       open,
+			wantOpen: 3,
+		},
+		{
+			name:            "flipped triangle",
+			in:              flipped,
+			wantClosed:      true,
+			wantMisoriented: [][2]int{{2, 3}, {2, 5}, {2, 11}},
+		},
+		{
+			name:       "inside out",
+			in:         insideOut,
+			wantClosed: true,
+		},
+		{
+			name:       "fin",
+			in:         fin,
+			wantOpen:   2,
+			wantNonMan: 1,
+		},
+		{
+			name:         "touching corners",
+			in:           corner,
+			wantClosed:   true,
+			wantOutward:  true,
+			wantVertices: 1,
+		},
+		{
+			// The repeat is left out of the edge checks, so the box is still closed
+			name:           "duplicate",
+			in:             duplicate,
+			wantClosed:     true,
+			wantOutward:    true,
+			wantDuplicates: [][2]int{{4, 12}},
+		},
+		{
+			name:           "degenerate",
+			in:             degenerate,
+			wantClosed:     true,
+			wantOutward:    true,
+			wantDegenerate: []int{12},
+		},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			r := tst.in.Validate(0)
+
+			if r.OK() != tst.wantOK || r.Closed != tst.wantClosed || r.Outward != tst.wantOutward {
+				t.Errorf("got ok %t, closed %t, outward %t; want %t, %t, %t", r.OK(), r.Closed, r.Outward, tst.wantOK, tst.wantClosed, tst.wantOutward)
+			}
+			if len(r.OpenEdges) != tst.wantOpen || len(r.NonManifoldEdges) != tst.wantNonMan || len(r.NonManifoldVertices) != tst.wantVertices {
+				t.Errorf("got %d open, %d non-manifold edges, %d non-manifold vertices; want %d, %d, %d",
+					len(r.OpenEdges), len(r.NonManifoldEdges), len(r.NonManifoldVertices), tst.wantOpen, tst.wantNonMan, tst.wantVertices)
+			}
+			if !equalPairs(r.Misoriented, tst.wantMisoriented) {
+				t.Errorf("got misoriented %v; want %v", r.Misoriented, tst.wantMisoriented)
+			}
+			if !equalPairs(r.Duplicates, tst.wantDuplicates) {
+				t.Errorf("got duplicates %v; want %v", r.Duplicates, tst.wantDuplicates)
+			}
+			if len(r.Degenerate) != len(tst.wantDegenerate) || len(r.Degenerate) > 0 && r.Degenerate[0] != tst.wantDegenerate[0] {
+				t.Errorf("got degenerate %v; want %v", r.Degenerate, tst.wantDegenerate)
+			}
+		})
+	}
+}
+func TestSolid_ValidateEdges(t *testing.T) {
+	s := testBox(1, 2, 3)
+	s.Triangles = s.Triangles[1:]
+
+	// The hole left by the first triangle of the bottom face
+	r := s.Validate(0)
+	want := map[Coordinate]int{{}: 2, {Y: 2}: 2, {X: 1, Y: 2}: 2}
+	for _, e := range r.OpenEdges {
+		want[e.A]--
+		want[e.B]--
+		if len(e.Triangles) != 1 {
+			t.Errorf("got %v using open edge; want one triangle", e.Triangles)
+		}
+	}
+	for c, n := range want {
+		if n != 0 {
+			t.Errorf("got vertex %+v on %d too few open edges", c, n)
+		}
+	}
+}
+func equalPairs(a, b [][2]int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
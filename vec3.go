@@ -0,0 +1,74 @@
+package stl
+
+import "math"
+
+// Vec3 is a point or direction in float64, used for calculations on the float32 Coordinates
+type Vec3 struct {
+	X float64
+	Y float64
+	Z float64
+}
+
+// Vec3 is the Coordinate in float64
+func (c Coordinate) Vec3() Vec3 {
+	return Vec3{X: float64(c.X), Y: float64(c.Y), Z: float64(c.Z)}
+}
+
+// Vec3 is the UnitVector in float64
+func (u UnitVector) Vec3() Vec3 {
+	return Vec3{X: float64(u.Ni), Y: float64(u.Nj), Z: float64(u.Nk)}
+}
+
+// Coordinate is the Vec3 rounded to float32
+func (v Vec3) Coordinate() Coordinate {
+	return Coordinate{X: float32(v.X), Y: float32(v.Y), Z: float32(v.Z)}
+}
+
+// UnitVector is the Vec3 rounded to float32.  It is not normalized.
+func (v Vec3) UnitVector() UnitVector {
+	return UnitVector{Ni: float32(v.X), Nj: float32(v.Y), Nk: float32(v.Z)}
+}
+
+// Add is v + w
+func (v Vec3) Add(w Vec3) Vec3 {
+	return Vec3{X: v.X + w.X, Y: v.Y + w.Y, Z: v.Z + w.Z}
+}
+
+// Sub is v - w
+func (v Vec3) Sub(w Vec3) Vec3 {
+	return Vec3{X: v.X - w.X, Y: v.Y - w.Y, Z: v.Z - w.Z}
+}
+
+// Scale is v * f
+func (v Vec3) Scale(f float64) Vec3 {
+	return Vec3{X: v.X * f, Y: v.Y * f, Z: v.Z * f}
+}
+
+// Dot is the dot product of v and w
+func (v Vec3) Dot(w Vec3) float64 {
+	return v.X*w.X + v.Y*w.Y + v.Z*w.Z
+}
+
+// Cross is the cross product of v and w
+func (v Vec3) Cross(w Vec3) Vec3 {
+	return Vec3{
+		X: v.Y*w.Z - v.Z*w.Y,
+		Y: v.Z*w.X - v.X*w.Z,
+		Z: v.X*w.Y - v.Y*w.X,
+	}
+}
+
+// Len is the length of v
+func (v Vec3) Len() float64 {
+	return math.Sqrt(v.Dot(v))
+}
+
+// Normalize is v scaled to a length of 1.  The zero vector is returned as is.
+func (v Vec3) Normalize() Vec3 {
+	l := v.Len()
+	if l == 0 {
+		return v
+	}
+
+	return v.Scale(1 / l)
+}
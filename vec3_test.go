@@ -0,0 +1,23 @@
+package stl
+
+import "testing"
+
+func TestVec3(t *testing.T) {
+	x, y := Vec3{X: 1}, Vec3{Y: 2}
+
+	if got := x.Cross(y); got != (Vec3{Z: 2}) {
+		t.Errorf("got %+v for cross; want {0 0 2}", got)
+	}
+	if got := x.Add(y).Sub(x); got != y {
+		t.Errorf("got %+v for add and sub; want %+v", got, y)
+	}
+	if got := y.Scale(2).Dot(y); got != 8 {
+		t.Errorf("got %g for dot; want 8", got)
+	}
+	if got := (Vec3{X: 3, Y: 4}).Normalize(); !nearVec(got, Vec3{X: 0.6, Y: 0.8}) {
+		t.Errorf("got %+v for normalize; want {0.6 0.8 0}", got)
+	}
+	if got := (Vec3{}).Normalize(); got != (Vec3{}) {
+		t.Errorf("got %+v for normalizing zero; want zero", got)
+	}
+}
@@ -0,0 +1,185 @@
+package stl
+
+import (
+	"archive/zip"
+	"bufio"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+const contentTypes3MF = `<?xml version="1.0" encoding="UTF-8"?>
+<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">
+ <Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>
+ <Default Extension="model" ContentType="application/vnd.ms-package.3dmanufacturing-3dmodel+xml"/>
+</Types>
+`
+
+const rels3MF = `<?xml version="1.0" encoding="UTF-8"?>
+<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">
+ <Relationship Target="/3D/3dmodel.model" Id="rel0" Type="` + rel3DModel + `"/>
+</Relationships>
+`
+
+// To3MF writes the Solids out as the objects of a 3MF package
+// See stl.To3MFTransformed for more info
+func To3MF(w io.Writer, solids []Solid) error {
+	return To3MFTransformed(w, solids, nil)
+}
+
+// To3MFTransformed writes the Solids out as the objects of a 3MF package, in
+// millimeters and named by their Headers.  Each object gets a build item placing
+// it with the transform of the same index, or none when transforms is shorter.
+// Identical vertices are written once and shared by the triangles using them, and
+// Triangles with two identical corners are left out.
+// Solids with a ColorFormat get a base material for each color used.
+// Use From3MF to read them back.
+func To3MFTransformed(w io.Writer, solids []Solid, transforms []Matrix) error {
+	zw := zip.NewWriter(w)
+
+	for _, part := range []struct{ name, content string }{
+		{"[Content_Types].xml", contentTypes3MF},
+		{"_rels/.rels", rels3MF},
+	} {
+		pw, err := zw.Create(part.name)
+		if err != nil {
+			return fmt.Errorf("did not write %s: %w", part.name, err)
+		}
+		if _, err := io.WriteString(pw, part.content); err != nil {
+			return fmt.Errorf("did not write %s: %w", part.name, err)
+		}
+	}
+
+	pw, err := zw.Create("3D/3dmodel.model")
+	if err != nil {
+		return fmt.Errorf("did not write model: %w", err)
+	}
+	bw := bufio.NewWriter(pw)
+	write3MFModel(bw, solids, transforms)
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write model: %w", err)
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("did not write package: %w", err)
+	}
+
+	return nil
+}
+
+// To3MFFile writes the Solids to a file in 3MF format
+// See stl.To3MF for more info
+func To3MFFile(filename string, solids []Solid) error {
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return To3MF(file, solids)
+}
+
+// write3MFModel writes the model part.  Errors are left to the caller's Flush.
+func write3MFModel(bw *bufio.Writer, solids []Solid, transforms []Matrix) {
+	bw.WriteString(`<?xml version="1.0" encoding="UTF-8"?>` + "\n")
+	bw.WriteString(`<model unit="millimeter" xml:lang="en-US" xmlns="http://schemas.microsoft.com/3dmanufacturing/core/2015/02">` + "\n")
+	bw.WriteString(" <resources>\n")
+
+	// Objects and material groups share the resource ids
+	id := 1
+	objects := make([]int, len(solids))
+	for i := range solids {
+		s := &solids[i]
+		mesh, _ := s.Indexed(0)
+
+		pid, props := 0, []int(nil)
+		if s.ColorFormat != ColorNone {
+			pid, props = id, write3MFMaterials(bw, id, s)
+			id++
+		}
+
+		objects[i] = id
+		fmt.Fprintf(bw, `  <object id="%d" type="model"`, id)
+		if name := headerName(s.Header); name != "" {
+			bw.WriteString(` name="`)
+			xml.EscapeText(bw, []byte(name))
+			bw.WriteByte('"')
+		}
+		if pid != 0 {
+			fmt.Fprintf(bw, ` pid="%d" pindex="0"`, pid)
+		}
+		bw.WriteString(">\n   <mesh>\n    <vertices>\n")
+		for _, v := range mesh.Vertices {
+			fmt.Fprintf(bw, "     <vertex x=\"%s\" y=\"%s\" z=\"%s\"/>\n", shortFloat(v.X), shortFloat(v.Y), shortFloat(v.Z))
+		}
+		bw.WriteString("    </vertices>\n    <triangles>\n")
+		// 3MF forbids a triangle using a vertex twice, so faces welded into a line are left out
+		collapsed := collapsedFaces(mesh)
+		for j, f := range mesh.Faces {
+			if collapsed[j] {
+				continue
+			}
+			fmt.Fprintf(bw, `     <triangle v1="%d" v2="%d" v3="%d"`, f.Vertices[0], f.Vertices[1], f.Vertices[2])
+			if pid != 0 && props[j] != 0 {
+				fmt.Fprintf(bw, ` pid="%d" p1="%d"`, pid, props[j])
+			}
+			bw.WriteString("/>\n")
+		}
+		bw.WriteString("    </triangles>\n   </mesh>\n  </object>\n")
+		id++
+	}
+
+	bw.WriteString(" </resources>\n <build>\n")
+	for i, obj := range objects {
+		fmt.Fprintf(bw, `  <item objectid="%d"`, obj)
+		if i < len(transforms) {
+			m := transforms[i]
+			bw.WriteString(` transform="`)
+			for k := range 12 {
+				if k > 0 {
+					bw.WriteByte(' ')
+				}
+				fmt.Fprintf(bw, "%g", m[k%3][k/3])
+			}
+			bw.WriteByte('"')
+		}
+		bw.WriteString("/>\n")
+	}
+	bw.WriteString(" </build>\n</model>\n")
+}
+
+// write3MFMaterials writes a base material group with the colors of s, the first
+// being the color of the whole Solid.  It returns the material of each Triangle.
+func write3MFMaterials(bw *bufio.Writer, id int, s *Solid) []int {
+	def := Color{R: 255, G: 255, B: 255, A: 255}
+	if s.Color != nil {
+		def = *s.Color
+	}
+
+	colors := []Color{def}
+	index := map[Color]int{def: 0}
+	props := make([]int, len(s.Triangles))
+	for i := range s.Triangles {
+		c, ok := s.Triangles[i].Color(s.ColorFormat)
+		if !ok {
+			continue
+		}
+		p, seen := index[c]
+		if !seen {
+			p = len(colors)
+			index[c] = p
+			colors = append(colors, c)
+		}
+		props[i] = p
+	}
+
+	fmt.Fprintf(bw, "  <basematerials id=\"%d\">\n", id)
+	for i, c := range colors {
+		fmt.Fprintf(bw, "   <base name=\"color%d\" displaycolor=\"#%02X%02X%02X%02X\"/>\n", i, c.R, c.G, c.B, c.A)
+	}
+	bw.WriteString("  </basematerials>\n")
+
+	return props
+}
@@ -0,0 +1,69 @@
+package stl
+
+import (
+	"bufio"
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestTo3MF(t *testing.T) {
+	red := Color{R: 255, A: 255}
+	box := testBox(1, 2, 3)
+	box.Header = `a "box" & more`
+	box.ColorFormat = ColorVisCAM
+	box.Triangles[3].SetColor(ColorVisCAM, red)
+	plain := testBox(1, 1, 1)
+	plain.Header = "box\x00\x00\x00"
+	plain.Triangles = append(plain.Triangles, Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 1}}})
+
+	var buf bytes.Buffer
+	if err := To3MFTransformed(&buf, []Solid{box, plain}, []Matrix{Identity(), Translate(5, 0, 0)}); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+
+	solids, err := From3MF(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("could not read back: %v", err)
+	}
+	if len(solids) != 2 {
+		t.Fatalf("got %d solids; want 2", len(solids))
+	}
+
+	s := solids[0]
+	if s.Header != box.Header || len(s.Triangles) != len(box.Triangles) {
+		t.Fatalf("got %q with %d triangles; want %q with %d", s.Header, len(s.Triangles), box.Header, len(box.Triangles))
+	}
+	for i := range box.Triangles {
+		if s.Triangles[i].Vertices != box.Triangles[i].Vertices || s.Triangles[i].Normal != box.Triangles[i].Normal {
+			t.Errorf("got triangle %d %v; want %v", i, s.Triangles[i], box.Triangles[i])
+		}
+	}
+	if c, ok := s.FacetColor(3); !ok || c != red {
+		t.Errorf("got color %v, %t; want red", c, ok)
+	}
+	if c, ok := s.FacetColor(0); !ok || c != (Color{R: 255, G: 255, B: 255, A: 255}) {
+		t.Errorf("got color %v, %t; want white", c, ok)
+	}
+
+	// The build item moved the second box
+	s = solids[1]
+	if s.Header != "box" || s.ColorFormat != ColorNone || len(s.Triangles) != 12 {
+		t.Errorf("got %q with %s colors and %d triangles; want box without colors or the collapsed triangle", s.Header, s.ColorFormat, len(s.Triangles))
+	}
+	if got, want := s.BoundingBox(), (Box{Min: Vec3{X: 5}, Max: Vec3{X: 6, Y: 1, Z: 1}}); got != want {
+		t.Errorf("got bounds %v; want %v", got, want)
+	}
+}
+func TestTo3MFNoName(t *testing.T) {
+	box := testBox(1, 1, 1)
+	box.Header = "\x00\x00"
+
+	var buf bytes.Buffer
+	bw := bufio.NewWriter(&buf)
+	write3MFModel(bw, []Solid{box}, nil)
+	bw.Flush()
+	if out := buf.String(); strings.Contains(out, "name=") {
+		t.Errorf("got model %q; want an object without a name", out)
+	}
+}
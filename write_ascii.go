@@ -7,27 +7,71 @@ import (
 	"math"
 	"os"
 	"strings"
+	"unicode"
 )
 
+// WriteOptions control how ToASCIIWithOptions and ToBinaryWithOptions write a Solid
+type WriteOptions struct {
+	// RecomputeNormals writes the normal given by the winding of each Triangle
+	// instead of the stored one, leaving the Solid unchanged.  See Solid.RecomputeNormals
+	RecomputeNormals bool
+}
+
 // ToASCII writes the Solid out in ASCII form
 func (s *Solid) ToASCII(w io.Writer) error {
+	return s.ToASCIIWithOptions(w, WriteOptions{})
+}
+
+// ToASCIIWithOptions writes the Solid out in ASCII form as opts says
+func (s *Solid) ToASCIIWithOptions(w io.Writer, opts WriteOptions) error {
 	bw := bufio.NewWriter(w)
-	defer bw.Flush()
+	if err := s.writeASCII(bw, opts); err != nil {
+		return err
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write solid: %w", err)
+	}
+
+	return nil
+}
+
+// ToASCIIMulti writes each Solid out in ASCII form, one after another, as a single output.
+// Each Header is used as the name of its solid.
+// Use FromMulti to read them back.
+func ToASCIIMulti(w io.Writer, solids []Solid) error {
+	bw := bufio.NewWriter(w)
+
+	for i := range solids {
+		if err := solids[i].writeASCII(bw, WriteOptions{}); err != nil {
+			return err
+		}
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write solids: %w", err)
+	}
 
+	return nil
+}
+func (s *Solid) writeASCII(bw *bufio.Writer, opts WriteOptions) error {
 	_, err := bw.WriteString("solid " + s.Header + "\n")
 	if err != nil {
-		return fmt.Errorf("did not write header: %v", err)
+		return fmt.Errorf("did not write header: %w", err)
 	}
 
 	for _, t := range s.Triangles {
+		if opts.RecomputeNormals {
+			t.Normal = t.ComputeNormal()
+		}
 		if _, err := bw.WriteString(triangleASCII(t)); err != nil {
-			return fmt.Errorf("did not write triangle: %v", err)
+			return fmt.Errorf("did not write triangle: %w", err)
 		}
 	}
 
 	_, err = bw.WriteString("endsolid " + s.Header + "\n")
 	if err != nil {
-		return fmt.Errorf("did not write footer: %v", err)
+		return fmt.Errorf("did not write footer: %w", err)
 	}
 
 	return nil
@@ -36,7 +80,7 @@ func (s *Solid) ToASCII(w io.Writer) error {
 // ToASCIIFile writes the Solid to a file in ASCII format
 // See stl.ToASCII for more info
 func (s *Solid) ToASCIIFile(filename string) error {
-	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE, 0700)
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
 	if err != nil {
 		return err
 	}
@@ -44,6 +88,18 @@ func (s *Solid) ToASCIIFile(filename string) error {
 
 	return s.ToASCII(file)
 }
+
+// ToASCIIMultiFile writes each Solid to a file in ASCII format
+// See stl.ToASCIIMulti for more info
+func ToASCIIMultiFile(filename string, solids []Solid) error {
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ToASCIIMulti(file, solids)
+}
 func triangleASCII(t Triangle) string {
 	return fmt.Sprintf(" facet normal %s %s %s\n", shortFloat(t.Normal.Ni), shortFloat(t.Normal.Nj), shortFloat(t.Normal.Nk)) +
 		"  outer loop\n" +
@@ -67,3 +123,9 @@ func shortFloat(f float32) string {
 
 	return sn
 }
+
+// headerName is the Header as a single line, without the NUL padding of binary
+// input, for formats that name what they hold
+func headerName(header string) string {
+	return strings.Join(strings.FieldsFunc(header, func(r rune) bool { return r == 0 || unicode.IsSpace(r) }), " ")
+}
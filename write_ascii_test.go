@@ -1,7 +1,11 @@
 package stl
 
 import (
+	"bytes"
+	"errors"
 	"fmt"
+	"io"
+	"path/filepath"
 	"testing"
 )
 
@@ -83,3 +87,82 @@ func Test_triangleASCII(t *testing.T) {
 		t.Errorf("got \n%s, \nwant \n%s", got, want)
 	}
 }
+func TestToASCIIMulti(t *testing.T) {
+	first := testSolid()
+	second := Solid{Header: "second", TriangleCount: 1, Triangles: first.Triangles[:1]}
+	solids := []Solid{first, {Header: "empty"}, second}
+
+	buf := &bytes.Buffer{}
+	if err := ToASCIIMulti(buf, solids); err != nil {
+		t.Fatalf("could not write solids: %v", err)
+	}
+
+	got, err := FromMulti(buf)
+	if err != nil {
+		t.Fatalf("could not read solids: %v", err)
+	}
+	if len(got) != len(solids) {
+		t.Fatalf("got %d solids; want %d", len(got), len(solids))
+	}
+	for i := range got {
+		if got[i].Header != solids[i].Header || got[i].TriangleCount != uint32(len(solids[i].Triangles)) {
+			t.Errorf("got %q with %d triangles; want %q with %d", got[i].Header, got[i].TriangleCount, solids[i].Header, len(solids[i].Triangles))
+		}
+		for j := range got[i].Triangles {
+			if got[i].Triangles[j] != solids[i].Triangles[j] {
+				t.Errorf("got %+v for triangle %d of solid %d; want %+v", got[i].Triangles[j], j, i, solids[i].Triangles[j])
+			}
+		}
+	}
+}
+func TestSolid_ToFlushError(t *testing.T) {
+	s := testSolid()
+
+	// Small outputs are only written when flushed
+	for name, write := range map[string]func(io.Writer) error{
+		"ASCII":  s.ToASCII,
+		"binary": s.ToBinary,
+	} {
+		if err := write(failWriter{}); !errors.Is(err, errFailWriter) {
+			t.Errorf("got %v writing %s; want %v", err, name, errFailWriter)
+		}
+	}
+}
+
+var errFailWriter = errors.New("write failed")
+
+// failWriter fails every write
+type failWriter struct{}
+
+func (failWriter) Write([]byte) (int, error) {
+	return 0, errFailWriter
+}
+func TestSolid_ToFileTruncates(t *testing.T) {
+	big := testSolid()
+	small := testSolid()
+	small.Triangles = small.Triangles[:1]
+	small.TriangleCount = 1
+
+	for name, write := range map[string]func(*Solid, string) error{
+		"ASCII":       (*Solid).ToASCIIFile,
+		"binary":      (*Solid).ToBinaryFile,
+		"ASCII multi": func(s *Solid, f string) error { return ToASCIIMultiFile(f, []Solid{*s}) },
+	} {
+		// Writing over a larger file leaves nothing of it behind
+		filename := filepath.Join(t.TempDir(), "out.stl")
+		if err := write(&big, filename); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+		if err := write(&small, filename); err != nil {
+			t.Fatalf("could not write %s: %v", name, err)
+		}
+
+		s, err := FromFile(filename)
+		if err != nil {
+			t.Fatalf("could not read %s back: %v", name, err)
+		}
+		if len(s.Triangles) != 1 {
+			t.Errorf("got %d %s triangles; want 1", len(s.Triangles), name)
+		}
+	}
+}
@@ -11,32 +11,49 @@ import (
 	"strings"
 )
 
-// ToBinary writes the Solid out in binary form
+// ToBinary writes the Solid out in binary form.
+// The triangle count written is the number of Triangles, whatever TriangleCount says.
 func (s *Solid) ToBinary(w io.Writer) error {
+	return s.ToBinaryWithOptions(w, WriteOptions{})
+}
+
+// ToBinaryWithOptions writes the Solid out in binary form as opts says
+// See stl.ToBinary for more info
+func (s *Solid) ToBinaryWithOptions(w io.Writer, opts WriteOptions) error {
+	if uint64(len(s.Triangles)) > math.MaxUint32 {
+		return fmt.Errorf("binary format cannot hold more than %d triangles", uint32(math.MaxUint32))
+	}
+
 	bw := bufio.NewWriter(w)
-	defer bw.Flush()
 
-	if _, err := bw.Write(headerBinary(s.Header)); err != nil {
-		return fmt.Errorf("did not write header: %v", err)
+	if _, err := bw.Write(headerBinary(binaryHeaderText(s))); err != nil {
+		return fmt.Errorf("did not write header: %w", err)
 	}
 
-	if _, err := bw.Write(triCountBinary(s.TriangleCount)); err != nil {
-		return fmt.Errorf("did not write triangle count: %v", err)
+	if _, err := bw.Write(triCountBinary(uint32(len(s.Triangles)))); err != nil {
+		return fmt.Errorf("did not write triangle count: %w", err)
 	}
 
 	for _, t := range s.Triangles {
+		if opts.RecomputeNormals {
+			t.Normal = t.ComputeNormal()
+		}
 		if _, err := bw.Write(triangleBinary(t)); err != nil {
-			return fmt.Errorf("did not write triangle: %v", err)
+			return fmt.Errorf("did not write triangle: %w", err)
 		}
 	}
 
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write solid: %w", err)
+	}
+
 	return nil
 }
 
 // ToBinaryFile writes the Solid to a file in binary format
 // See stl.ToBinary for more info
 func (s *Solid) ToBinaryFile(filename string) error {
-	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE, 0700)
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
 	if err != nil {
 		return err
 	}
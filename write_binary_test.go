@@ -160,3 +160,19 @@ func Test_triangleBinary(t *testing.T) {
 		})
 	}
 }
+func TestSolid_ToBinaryCount(t *testing.T) {
+	// A stale TriangleCount must not reach the output
+	s := testBox(1, 1, 1)
+	s.TriangleCount = 99
+
+	var buf bytes.Buffer
+	if err := s.ToBinary(&buf); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if got := buf.Bytes()[80:84]; !bytes.Equal(got, triCountBinary(12)) {
+		t.Errorf("got count %x; want %x", got, triCountBinary(12))
+	}
+	if buf.Len() != 84+50*12 {
+		t.Errorf("got %d bytes; want %d", buf.Len(), 84+50*12)
+	}
+}
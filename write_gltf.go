@@ -0,0 +1,380 @@
+package stl
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// GLTFOptions control the vertices written by ToGLTF and ToGLB
+type GLTFOptions struct {
+	// CreaseAngle is the angle in degrees beyond which neighboring faces keep their own
+	// normals at a shared vertex.  Faces meeting at a smaller angle share a normal
+	// weighted by the angle of each face at the vertex, giving a smooth look.
+	// Zero gives every face a flat normal.
+	CreaseAngle float64
+	// Colors adds a vertex color from Solid.FacetColor, or white for a face without one,
+	// when the Solid has a ColorFormat
+	Colors bool
+}
+
+// glTF constants
+const (
+	gltfFloat         = 5126
+	gltfUnsignedShort = 5123
+	gltfUnsignedInt   = 5125
+	gltfArrayBuffer   = 34962
+	gltfElementBuffer = 34963
+	gltfTriangles     = 4
+
+	glbMagic     = 0x46546C67
+	glbChunkJSON = 0x4E4F534A
+	glbChunkBIN  = 0x004E4942
+)
+
+// ToGLTF writes the Solid out as a glTF 2.0 document to w, with its vertex data
+// to bin.  The document refers to the data by uri, which is usually the name
+// of the .bin file next to it.  Identical vertices with the same normal and color are
+// written once and shared by the faces using them.  Coordinates are written as they
+// are, while glTF viewers take them as meters, so millimeters may need a Transform
+// by ScaleUniform(0.001) first.
+func (s *Solid) ToGLTF(w, bin io.Writer, uri string, opts GLTFOptions) error {
+	doc, data, err := s.gltf(uri, opts)
+	if err != nil {
+		return err
+	}
+
+	if _, err := w.Write(doc); err != nil {
+		return fmt.Errorf("did not write glTF: %w", err)
+	}
+	if _, err := bin.Write(data); err != nil {
+		return fmt.Errorf("did not write glTF buffer: %w", err)
+	}
+
+	return nil
+}
+
+// ToGLTFFile writes the Solid to a glTF file, with its vertex data in a .bin file
+// of the same name next to it.  A filename already ending in .bin gets a second
+// .bin for the data, so the document does not overwrite it.
+// See stl.ToGLTF for more info
+func (s *Solid) ToGLTFFile(filename string, opts GLTFOptions) error {
+	filename = strings.TrimSpace(filename)
+	binName := strings.TrimSuffix(filename, filepath.Ext(filename)) + ".bin"
+	if binName == filename {
+		binName += ".bin"
+	}
+
+	doc, data, err := s.gltf(filepath.Base(binName), opts)
+	if err != nil {
+		return err
+	}
+
+	if err := os.WriteFile(binName, data, 0700); err != nil {
+		return err
+	}
+
+	return os.WriteFile(filename, doc, 0700)
+}
+
+// ToGLB writes the Solid out as a single binary glTF 2.0 file holding both the
+// document and its vertex data
+// See stl.ToGLTF for more info
+func (s *Solid) ToGLB(w io.Writer, opts GLTFOptions) error {
+	doc, data, err := s.gltf("", opts)
+	if err != nil {
+		return err
+	}
+
+	// Chunks are padded to 4 bytes, the document with spaces
+	for len(doc)%4 != 0 {
+		doc = append(doc, ' ')
+	}
+	for len(data)%4 != 0 {
+		data = append(data, 0)
+	}
+
+	out := make([]byte, 0, 28+len(doc)+len(data))
+	out = binary.LittleEndian.AppendUint32(out, glbMagic)
+	out = binary.LittleEndian.AppendUint32(out, 2)
+	out = binary.LittleEndian.AppendUint32(out, uint32(28+len(doc)+len(data)))
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(doc)))
+	out = binary.LittleEndian.AppendUint32(out, glbChunkJSON)
+	out = append(out, doc...)
+	out = binary.LittleEndian.AppendUint32(out, uint32(len(data)))
+	out = binary.LittleEndian.AppendUint32(out, glbChunkBIN)
+	out = append(out, data...)
+
+	if _, err := w.Write(out); err != nil {
+		return fmt.Errorf("did not write GLB: %w", err)
+	}
+
+	return nil
+}
+
+// ToGLBFile writes the Solid to a file in GLB format
+// See stl.ToGLB for more info
+func (s *Solid) ToGLBFile(filename string, opts GLTFOptions) error {
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.ToGLB(file, opts)
+}
+
+// gltfDoc is the part of a glTF document written
+type gltfDoc struct {
+	Asset       gltfAsset        `json:"asset"`
+	Scene       int              `json:"scene"`
+	Scenes      []gltfScene      `json:"scenes"`
+	Nodes       []gltfNode       `json:"nodes"`
+	Meshes      []gltfMeshDoc    `json:"meshes"`
+	Materials   []gltfMaterial   `json:"materials"`
+	Accessors   []gltfAccessor   `json:"accessors"`
+	BufferViews []gltfBufferView `json:"bufferViews"`
+	Buffers     []gltfBuffer     `json:"buffers"`
+}
+type gltfAsset struct {
+	Version   string `json:"version"`
+	Generator string `json:"generator,omitempty"`
+}
+type gltfScene struct {
+	Nodes []int `json:"nodes"`
+}
+type gltfNode struct {
+	Name string `json:"name,omitempty"`
+	Mesh int    `json:"mesh"`
+}
+type gltfMeshDoc struct {
+	Name       string          `json:"name,omitempty"`
+	Primitives []gltfPrimitive `json:"primitives"`
+}
+type gltfPrimitive struct {
+	Attributes map[string]int `json:"attributes"`
+	Indices    int            `json:"indices"`
+	Material   int            `json:"material"`
+	Mode       int            `json:"mode"`
+}
+type gltfMaterial struct {
+	PBR struct {
+		BaseColorFactor [4]float64 `json:"baseColorFactor"`
+		MetallicFactor  float64    `json:"metallicFactor"`
+		RoughnessFactor float64    `json:"roughnessFactor"`
+	} `json:"pbrMetallicRoughness"`
+}
+type gltfAccessor struct {
+	BufferView    int       `json:"bufferView"`
+	ComponentType int       `json:"componentType"`
+	Count         int       `json:"count"`
+	Type          string    `json:"type"`
+	Min           []float32 `json:"min,omitempty"`
+	Max           []float32 `json:"max,omitempty"`
+}
+type gltfBufferView struct {
+	Buffer     int `json:"buffer"`
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+	Target     int `json:"target"`
+}
+type gltfBuffer struct {
+	URI        string `json:"uri,omitempty"`
+	ByteLength int    `json:"byteLength"`
+}
+
+// gltf builds the document, referring to the data by uri, and the data
+func (s *Solid) gltf(uri string, opts GLTFOptions) (doc, data []byte, err error) {
+	if len(s.Triangles) == 0 {
+		return nil, nil, fmt.Errorf("cannot write glTF: %w", ErrNoTriangles)
+	}
+
+	vertices, indices := s.gltfVertices(opts)
+	colored := opts.Colors && s.ColorFormat != ColorNone
+
+	d := gltfDoc{
+		Asset:     gltfAsset{Version: "2.0", Generator: "gitlab.com/russoj88/stl"},
+		Scenes:    []gltfScene{{Nodes: []int{0}}},
+		Nodes:     []gltfNode{{Name: headerName(s.Header), Mesh: 0}},
+		Meshes:    []gltfMeshDoc{{Name: headerName(s.Header)}},
+		Materials: make([]gltfMaterial, 1),
+	}
+	d.Materials[0].PBR.BaseColorFactor = [4]float64{1, 1, 1, 1}
+	d.Materials[0].PBR.RoughnessFactor = 1
+
+	var buf []byte
+	view := func(target int) int {
+		d.BufferViews = append(d.BufferViews, gltfBufferView{ByteOffset: len(buf), Target: target})
+		return len(d.BufferViews) - 1
+	}
+	end := func(v int) {
+		d.BufferViews[v].ByteLength = len(buf) - d.BufferViews[v].ByteOffset
+	}
+	vec3 := func(get func(gltfVertex) [3]float32, bounds bool) int {
+		v := view(gltfArrayBuffer)
+		a := gltfAccessor{BufferView: v, ComponentType: gltfFloat, Count: len(vertices), Type: "VEC3"}
+		if bounds {
+			a.Min = []float32{math.MaxFloat32, math.MaxFloat32, math.MaxFloat32}
+			a.Max = []float32{-math.MaxFloat32, -math.MaxFloat32, -math.MaxFloat32}
+		}
+		for _, vert := range vertices {
+			for k, f := range get(vert) {
+				buf = binary.LittleEndian.AppendUint32(buf, math.Float32bits(f))
+				if bounds {
+					a.Min[k] = min(a.Min[k], f)
+					a.Max[k] = max(a.Max[k], f)
+				}
+			}
+		}
+		end(v)
+		d.Accessors = append(d.Accessors, a)
+		return len(d.Accessors) - 1
+	}
+
+	prim := gltfPrimitive{Attributes: make(map[string]int), Mode: gltfTriangles}
+	prim.Attributes["POSITION"] = vec3(func(v gltfVertex) [3]float32 { return v.position }, true)
+	prim.Attributes["NORMAL"] = vec3(func(v gltfVertex) [3]float32 { return v.normal }, false)
+	if colored {
+		prim.Attributes["COLOR_0"] = vec3(func(v gltfVertex) [3]float32 { return v.color }, false)
+	}
+
+	// Indices use the smallest type that holds them
+	v := view(gltfElementBuffer)
+	a := gltfAccessor{BufferView: v, ComponentType: gltfUnsignedInt, Count: len(indices), Type: "SCALAR"}
+	if len(vertices) <= math.MaxUint16 {
+		a.ComponentType = gltfUnsignedShort
+	}
+	for _, i := range indices {
+		if a.ComponentType == gltfUnsignedShort {
+			buf = binary.LittleEndian.AppendUint16(buf, uint16(i))
+		} else {
+			buf = binary.LittleEndian.AppendUint32(buf, i)
+		}
+	}
+	end(v)
+	d.Accessors = append(d.Accessors, a)
+	prim.Indices = len(d.Accessors) - 1
+	d.Meshes[0].Primitives = []gltfPrimitive{prim}
+
+	// The buffer length is a multiple of 4, as the GLB chunk will be
+	for len(buf)%4 != 0 {
+		buf = append(buf, 0)
+	}
+	d.Buffers = []gltfBuffer{{URI: uri, ByteLength: len(buf)}}
+
+	doc, err = json.Marshal(d)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not encode glTF: %w", err)
+	}
+
+	return doc, buf, nil
+}
+
+// gltfVertex is a vertex with everything glTF stores per vertex
+type gltfVertex struct {
+	position [3]float32
+	normal   [3]float32
+	color    [3]float32
+}
+
+// gltfVertices finds the distinct vertices of the Solid and the indices of each face's corners
+func (s *Solid) gltfVertices(opts GLTFOptions) ([]gltfVertex, []uint32) {
+	mesh, _ := s.Indexed(0)
+
+	// Unit normals of each face, falling back to the stored normal for a face with
+	// no area, and the angle at each corner
+	unit := make([]Vec3, len(mesh.Faces))
+	angles := make([]float64, 3*len(mesh.Faces))
+	for i, f := range mesh.Faces {
+		var p [3]Vec3
+		for k, v := range f.Vertices {
+			p[k] = mesh.Vertices[v].Vec3()
+		}
+		unit[i] = p[1].Sub(p[0]).Cross(p[2].Sub(p[0])).Normalize()
+		if unit[i] == (Vec3{}) {
+			unit[i] = f.Normal.Vec3().Normalize()
+		}
+		for k := range p {
+			a := p[(k+1)%3].Sub(p[k]).Normalize()
+			b := p[(k+2)%3].Sub(p[k]).Normalize()
+			angles[3*i+k] = math.Acos(max(-1, min(1, a.Dot(b))))
+		}
+	}
+
+	// Corners at each vertex, as 3 times the face plus the corner
+	var incident [][]int
+	smooth := opts.CreaseAngle > 0
+	if smooth {
+		incident = make([][]int, len(mesh.Vertices))
+		for i, f := range mesh.Faces {
+			for k, v := range f.Vertices {
+				incident[v] = append(incident[v], 3*i+k)
+			}
+		}
+	}
+	crease := math.Cos(opts.CreaseAngle * math.Pi / 180)
+
+	colored := opts.Colors && s.ColorFormat != ColorNone
+	index := make(map[gltfVertex]uint32)
+	var vertices []gltfVertex
+	indices := make([]uint32, 0, 3*len(mesh.Faces))
+	for i, f := range mesh.Faces {
+		var vert gltfVertex
+		if colored {
+			c := Color{R: 255, G: 255, B: 255}
+			if fc, ok := s.FacetColor(i); ok {
+				c = fc
+			}
+			vert.color = [3]float32{linearColor(c.R), linearColor(c.G), linearColor(c.B)}
+		}
+
+		for _, v := range f.Vertices {
+			n := unit[i]
+			if smooth {
+				var sum Vec3
+				for _, c := range incident[v] {
+					if j := c / 3; unit[j].Dot(unit[i]) >= crease {
+						sum = sum.Add(unit[j].Scale(angles[c]))
+					}
+				}
+				if sum = sum.Normalize(); sum != (Vec3{}) {
+					n = sum
+				}
+			}
+
+			// glTF needs unit normals, even for a face with no area or stored normal
+			if n == (Vec3{}) {
+				n = Vec3{Z: 1}
+			}
+
+			p := mesh.Vertices[v]
+			vert.position = [3]float32{p.X, p.Y, p.Z}
+			vert.normal = [3]float32{float32(n.X), float32(n.Y), float32(n.Z)}
+
+			k, ok := index[vert]
+			if !ok {
+				k = uint32(len(vertices))
+				index[vert] = k
+				vertices = append(vertices, vert)
+			}
+			indices = append(indices, k)
+		}
+	}
+
+	return vertices, indices
+}
+
+// linearColor converts an 8 bit sRGB value to the linear value glTF vertex colors use
+func linearColor(c uint8) float32 {
+	f := float64(c) / 255
+	if f <= 0.04045 {
+		return float32(f / 12.92)
+	}
+
+	return float32(math.Pow((f+0.055)/1.055, 2.4))
+}
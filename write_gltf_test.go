@@ -0,0 +1,209 @@
+package stl
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// readGLB splits GLB output into its document and data
+func readGLB(t *testing.T, b []byte) (gltfDoc, []byte) {
+	t.Helper()
+
+	le := binary.LittleEndian
+	if len(b) < 20 || le.Uint32(b) != glbMagic || le.Uint32(b[4:]) != 2 || int(le.Uint32(b[8:])) != len(b) {
+		t.Fatalf("got bad GLB header % x", b[:min(len(b), 12)])
+	}
+	n := int(le.Uint32(b[12:]))
+	if le.Uint32(b[16:]) != glbChunkJSON || n%4 != 0 {
+		t.Fatalf("got bad JSON chunk of %d bytes", n)
+	}
+	var d gltfDoc
+	if err := json.Unmarshal(b[20:20+n], &d); err != nil {
+		t.Fatalf("could not decode document: %v", err)
+	}
+
+	bin := b[20+n:]
+	if le.Uint32(bin[4:]) != glbChunkBIN || int(le.Uint32(bin)) != len(bin)-8 {
+		t.Fatalf("got bad BIN chunk")
+	}
+
+	return d, bin[8:]
+}
+
+// gltfFloats reads the VEC3 accessor of the attribute
+func gltfFloats(t *testing.T, d gltfDoc, data []byte, attr string) [][3]float32 {
+	t.Helper()
+
+	i, ok := d.Meshes[0].Primitives[0].Attributes[attr]
+	if !ok {
+		t.Fatalf("got no %s attribute", attr)
+	}
+	a := d.Accessors[i]
+	v := d.BufferViews[a.BufferView]
+	if a.ComponentType != gltfFloat || a.Type != "VEC3" || v.ByteLength != 12*a.Count {
+		t.Fatalf("got %s accessor %+v with view %+v", attr, a, v)
+	}
+
+	out := make([][3]float32, a.Count)
+	for j := range out {
+		for k := range 3 {
+			out[j][k] = math.Float32frombits(binary.LittleEndian.Uint32(data[v.ByteOffset+12*j+4*k:]))
+		}
+	}
+
+	return out
+}
+
+func TestSolid_ToGLB(t *testing.T) {
+	box := testBox(1, 2, 3)
+
+	for _, tst := range []struct {
+		name         string
+		crease       float64
+		wantVertices int
+	}{
+		{name: "flat", wantVertices: 24},
+		{name: "below the edges", crease: 80, wantVertices: 24},
+		{name: "smooth", crease: 100, wantVertices: 8},
+	} {
+		tst := tst
+		t.Run(tst.name, func(t *testing.T) {
+			t.Parallel()
+			var buf bytes.Buffer
+			if err := box.ToGLB(&buf, GLTFOptions{CreaseAngle: tst.crease}); err != nil {
+				t.Fatalf("could not write: %v", err)
+			}
+			d, data := readGLB(t, buf.Bytes())
+			if d.Asset.Version != "2.0" || d.Buffers[0].URI != "" || d.Buffers[0].ByteLength != len(data) {
+				t.Errorf("got asset %+v and buffer %+v; want 2.0 with %d bytes inside", d.Asset, d.Buffers[0], len(data))
+			}
+
+			prim := d.Meshes[0].Primitives[0]
+			a := d.Accessors[prim.Attributes["POSITION"]]
+			if a.Count != tst.wantVertices || len(a.Min) != 3 || a.Min[0] != 0 || a.Max[0] != 1 || a.Max[1] != 2 || a.Max[2] != 3 {
+				t.Errorf("got %d vertices from %v to %v; want %d from the origin to {1 2 3}", a.Count, a.Min, a.Max, tst.wantVertices)
+			}
+			if idx := d.Accessors[prim.Indices]; idx.Count != 36 || idx.ComponentType != gltfUnsignedShort {
+				t.Errorf("got %d indices of type %d; want 36 unsigned shorts", idx.Count, idx.ComponentType)
+			}
+			if _, ok := prim.Attributes["COLOR_0"]; ok {
+				t.Errorf("got colors without asking")
+			}
+
+			// Flat normals are along an axis, smooth ones point out of a corner
+			for _, n := range gltfFloats(t, d, data, "NORMAL") {
+				axes := 0
+				for _, f := range n {
+					if f != 0 {
+						axes++
+					}
+				}
+				if l := math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])); math.Abs(l-1) > 1e-6 || (axes == 1) != (tst.crease < 90) {
+					t.Errorf("got normal %v", n)
+				}
+			}
+		})
+	}
+}
+func TestSolid_ToGLBDegenerate(t *testing.T) {
+	box := testBox(1, 1, 1)
+	box.Triangles = append(box.Triangles, Triangle{Vertices: [3]Coordinate{{}, {X: 1}, {X: 1}}})
+
+	for _, crease := range []float64{0, 30} {
+		var buf bytes.Buffer
+		if err := box.ToGLB(&buf, GLTFOptions{CreaseAngle: crease}); err != nil {
+			t.Fatalf("could not write: %v", err)
+		}
+		d, data := readGLB(t, buf.Bytes())
+		for _, n := range gltfFloats(t, d, data, "NORMAL") {
+			if l := math.Sqrt(float64(n[0]*n[0] + n[1]*n[1] + n[2]*n[2])); math.Abs(l-1) > 1e-6 {
+				t.Errorf("got normal %v with crease %v; want unit length", n, crease)
+			}
+		}
+	}
+}
+func TestSolid_ToGLBColors(t *testing.T) {
+	box := testBox(1, 1, 1)
+	box.Header = "\x00\x00"
+	box.ColorFormat = ColorVisCAM
+	box.Triangles[0].SetColor(ColorVisCAM, Color{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	if err := box.ToGLB(&buf, GLTFOptions{Colors: true}); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"name"`)) {
+		t.Errorf("got a name for a header of padding")
+	}
+	d, data := readGLB(t, buf.Bytes())
+
+	red, white := 0, 0
+	for _, c := range gltfFloats(t, d, data, "COLOR_0") {
+		switch c {
+		case [3]float32{1, 0, 0}:
+			red++
+		case [3]float32{1, 1, 1}:
+			white++
+		default:
+			t.Errorf("got color %v", c)
+		}
+	}
+	if red != 3 || white != 23 {
+		t.Errorf("got %d red and %d white vertices; want 3 and 23", red, white)
+	}
+
+	if got := linearColor(128); !near(float64(got), 0.2158605) {
+		t.Errorf("got linear %v; want 0.2158605", got)
+	}
+}
+func TestSolid_ToGLTFFile(t *testing.T) {
+	box := testBox(1, 1, 1)
+	box.Header = "box\x00\x00"
+	name := filepath.Join(t.TempDir(), "box.gltf")
+	if err := box.ToGLTFFile(name, GLTFOptions{}); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+
+	doc, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatalf("could not read document: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(name), "box.bin"))
+	if err != nil {
+		t.Fatalf("could not read data: %v", err)
+	}
+
+	var d gltfDoc
+	if err := json.Unmarshal(doc, &d); err != nil {
+		t.Fatalf("could not decode document: %v", err)
+	}
+	if d.Buffers[0].URI != "box.bin" || d.Buffers[0].ByteLength != len(data) || d.Nodes[0].Name != "box" {
+		t.Errorf("got buffer %+v and node %+v; want box.bin with %d bytes", d.Buffers[0], d.Nodes[0], len(data))
+	}
+	if got := len(gltfFloats(t, d, data, "POSITION")); got != 24 {
+		t.Errorf("got %d vertices; want 24", got)
+	}
+
+	// The document cannot take the name of its data
+	name = filepath.Join(filepath.Dir(name), "m.bin")
+	if err := box.ToGLTFFile(name, GLTFOptions{}); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if doc, err := os.ReadFile(name); err != nil || json.Unmarshal(doc, &d) != nil || d.Buffers[0].URI != "m.bin.bin" {
+		t.Errorf("got document %+v, %v; want data in m.bin.bin", d.Buffers, err)
+	}
+	if _, err := os.Stat(name + ".bin"); err != nil {
+		t.Errorf("could not find data: %v", err)
+	}
+
+	var empty Solid
+	if err := empty.ToGLTF(&bytes.Buffer{}, &bytes.Buffer{}, "x.bin", GLTFOptions{}); !errors.Is(err, ErrNoTriangles) {
+		t.Errorf("got %v; want %v", err, ErrNoTriangles)
+	}
+}
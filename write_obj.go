@@ -0,0 +1,93 @@
+package stl
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// ToOBJ writes the Solid out in Wavefront OBJ form
+// See stl.ToOBJMulti for more info
+func (s *Solid) ToOBJ(w io.Writer) error {
+	return ToOBJMulti(w, []Solid{*s})
+}
+
+// ToOBJMulti writes each Solid out as an object of a single Wavefront OBJ output,
+// named by its Header, or "solid" and its index when the Header is empty.
+// Identical vertices and normals are written once and shared by the faces using them.
+// Use FromOBJ to read them back.
+func ToOBJMulti(w io.Writer, solids []Solid) error {
+	bw := bufio.NewWriter(w)
+
+	// OBJ indexes count from 1 across the whole output
+	vbase, nbase := 1, 1
+	for i := range solids {
+		s := &solids[i]
+
+		name := headerName(s.Header)
+		if name == "" {
+			name = fmt.Sprintf("solid%d", i)
+		}
+		if _, err := bw.WriteString("o " + name + "\n"); err != nil {
+			return fmt.Errorf("did not write object: %w", err)
+		}
+
+		mesh, _ := s.Indexed(0)
+		for _, v := range mesh.Vertices {
+			if _, err := fmt.Fprintf(bw, "v %s %s %s\n", shortFloat(v.X), shortFloat(v.Y), shortFloat(v.Z)); err != nil {
+				return fmt.Errorf("did not write vertex: %w", err)
+			}
+		}
+
+		normals := make(map[UnitVector]int)
+		faceNormals := make([]int, len(mesh.Faces))
+		for j, f := range mesh.Faces {
+			n, ok := normals[f.Normal]
+			if !ok {
+				n = len(normals)
+				normals[f.Normal] = n
+				if _, err := fmt.Fprintf(bw, "vn %s %s %s\n", shortFloat(f.Normal.Ni), shortFloat(f.Normal.Nj), shortFloat(f.Normal.Nk)); err != nil {
+					return fmt.Errorf("did not write normal: %w", err)
+				}
+			}
+			faceNormals[j] = n + nbase
+		}
+
+		for j, f := range mesh.Faces {
+			n := faceNormals[j]
+			v := f.Vertices
+			if _, err := fmt.Fprintf(bw, "f %d//%d %d//%d %d//%d\n", v[0]+vbase, n, v[1]+vbase, n, v[2]+vbase, n); err != nil {
+				return fmt.Errorf("did not write face: %w", err)
+			}
+		}
+
+		vbase += len(mesh.Vertices)
+		nbase += len(normals)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write solids: %w", err)
+	}
+
+	return nil
+}
+
+// ToOBJFile writes the Solid to a file in OBJ format
+// See stl.ToOBJ for more info
+func (s *Solid) ToOBJFile(filename string) error {
+	return ToOBJMultiFile(filename, []Solid{*s})
+}
+
+// ToOBJMultiFile writes each Solid to a file in OBJ format
+// See stl.ToOBJMulti for more info
+func ToOBJMultiFile(filename string, solids []Solid) error {
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return ToOBJMulti(file, solids)
+}
@@ -0,0 +1,60 @@
+package stl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestToOBJMulti(t *testing.T) {
+	a := testBox(1, 2, 3)
+	a.Header = "first  box\x00\x00"
+	b := testBox(1, 1, 1)
+	b.Header = ""
+	b.Transform(Translate(5, 0, 0))
+
+	var buf bytes.Buffer
+	if err := ToOBJMulti(&buf, []Solid{a, b}); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	out := buf.String()
+
+	// Each box has 8 shared corners and 6 face normals
+	for _, tst := range []struct {
+		prefix string
+		want   int
+	}{
+		{prefix: "o ", want: 2},
+		{prefix: "v ", want: 16},
+		{prefix: "vn ", want: 12},
+		{prefix: "f ", want: 24},
+	} {
+		n := 0
+		for _, line := range strings.Split(out, "\n") {
+			if strings.HasPrefix(line, tst.prefix) {
+				n++
+			}
+		}
+		if n != tst.want {
+			t.Errorf("got %d %q lines; want %d", n, tst.prefix, tst.want)
+		}
+	}
+
+	solids, err := FromOBJ(&buf)
+	if err != nil {
+		t.Fatalf("could not read back: %v", err)
+	}
+	if len(solids) != 2 || solids[0].Header != "first box" || solids[1].Header != "solid1" {
+		t.Fatalf("got %d solids; want %q and %q", len(solids), "first box", "solid1")
+	}
+	for i, want := range []Solid{a, b} {
+		if len(solids[i].Triangles) != len(want.Triangles) {
+			t.Fatalf("got %d triangles in solid %d; want %d", len(solids[i].Triangles), i, len(want.Triangles))
+		}
+		for j := range want.Triangles {
+			if solids[i].Triangles[j] != want.Triangles[j] {
+				t.Errorf("got solid %d triangle %d %v; want %v", i, j, solids[i].Triangles[j], want.Triangles[j])
+			}
+		}
+	}
+}
@@ -0,0 +1,100 @@
+package stl
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"strings"
+)
+
+// ToPLY writes the Solid out in PLY form with the given encoding.
+// Identical vertices are written once and shared by the faces using them.
+// The Header is written as a comment.  When the Solid has a ColorFormat, each
+// face gets the red, green and blue of Solid.FacetColor, or white without one.
+// Use FromPLY to read it back.
+func (s *Solid) ToPLY(w io.Writer, enc PLYEncoding) error {
+	var order binary.AppendByteOrder
+	switch enc {
+	case PLYASCII:
+	case PLYBinaryLittleEndian:
+		order = binary.LittleEndian
+	case PLYBinaryBigEndian:
+		order = binary.BigEndian
+	default:
+		return fmt.Errorf("cannot encode PLY as %s", enc)
+	}
+
+	mesh, _ := s.Indexed(0)
+	colored := s.ColorFormat != ColorNone
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, "ply\nformat %s 1.0\n", enc)
+	if header := headerName(s.Header); header != "" {
+		fmt.Fprintf(bw, "comment %s\n", header)
+	}
+	fmt.Fprintf(bw, "element vertex %d\nproperty float x\nproperty float y\nproperty float z\n", len(mesh.Vertices))
+	fmt.Fprintf(bw, "element face %d\nproperty list uchar int vertex_indices\n", len(mesh.Faces))
+	if colored {
+		bw.WriteString("property uchar red\nproperty uchar green\nproperty uchar blue\n")
+	}
+	bw.WriteString("end_header\n")
+
+	buf := make([]byte, 0, 16)
+	for _, v := range mesh.Vertices {
+		if order == nil {
+			fmt.Fprintf(bw, "%s %s %s\n", shortFloat(v.X), shortFloat(v.Y), shortFloat(v.Z))
+			continue
+		}
+		buf = order.AppendUint32(buf[:0], math.Float32bits(v.X))
+		buf = order.AppendUint32(buf, math.Float32bits(v.Y))
+		buf = order.AppendUint32(buf, math.Float32bits(v.Z))
+		bw.Write(buf)
+	}
+
+	for i, f := range mesh.Faces {
+		c := Color{R: 255, G: 255, B: 255}
+		if colored {
+			if fc, ok := s.FacetColor(i); ok {
+				c = fc
+			}
+		}
+
+		if order == nil {
+			fmt.Fprintf(bw, "3 %d %d %d", f.Vertices[0], f.Vertices[1], f.Vertices[2])
+			if colored {
+				fmt.Fprintf(bw, " %d %d %d", c.R, c.G, c.B)
+			}
+			bw.WriteByte('\n')
+			continue
+		}
+		buf = append(buf[:0], 3)
+		for _, v := range f.Vertices {
+			buf = order.AppendUint32(buf, uint32(v))
+		}
+		if colored {
+			buf = append(buf, c.R, c.G, c.B)
+		}
+		bw.Write(buf)
+	}
+
+	if err := bw.Flush(); err != nil {
+		return fmt.Errorf("did not write PLY: %w", err)
+	}
+
+	return nil
+}
+
+// ToPLYFile writes the Solid to a file in PLY format
+// See stl.ToPLY for more info
+func (s *Solid) ToPLYFile(filename string, enc PLYEncoding) error {
+	file, err := os.OpenFile(strings.TrimSpace(filename), os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0700)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	return s.ToPLY(file, enc)
+}
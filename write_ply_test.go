@@ -0,0 +1,78 @@
+package stl
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestSolid_ToPLY(t *testing.T) {
+	red := Color{R: 255, A: 255}
+	box := testBox(1, 2, 3)
+	box.Header = "a  box"
+	box.ColorFormat = ColorVisCAM
+	box.Triangles[3].SetColor(ColorVisCAM, red)
+
+	for _, enc := range []PLYEncoding{PLYASCII, PLYBinaryLittleEndian, PLYBinaryBigEndian} {
+		var buf bytes.Buffer
+		if err := box.ToPLY(&buf, enc); err != nil {
+			t.Fatalf("could not write %s: %v", enc, err)
+		}
+		if !strings.Contains(buf.String(), "format "+enc.String()+" 1.0\ncomment a box\nelement vertex 8\n") {
+			t.Errorf("got %s header %q; want 8 shared vertices", enc, buf.String()[:80])
+		}
+
+		s, err := FromPLY(&buf)
+		if err != nil {
+			t.Fatalf("could not read %s back: %v", enc, err)
+		}
+		if s.Header != "a box" || len(s.Triangles) != len(box.Triangles) {
+			t.Fatalf("got %s %q with %d triangles; want %q with %d", enc, s.Header, len(s.Triangles), "a box", len(box.Triangles))
+		}
+		for i := range box.Triangles {
+			if s.Triangles[i].Vertices != box.Triangles[i].Vertices || s.Triangles[i].Normal != box.Triangles[i].Normal {
+				t.Errorf("got %s triangle %d %v; want %v", enc, i, s.Triangles[i], box.Triangles[i])
+			}
+		}
+		if c, ok := s.FacetColor(3); !ok || c != red {
+			t.Errorf("got %s color %v, %t; want red", enc, c, ok)
+		}
+		if c, ok := s.FacetColor(0); !ok || c != (Color{R: 255, G: 255, B: 255, A: 255}) {
+			t.Errorf("got %s color %v, %t; want white", enc, c, ok)
+		}
+	}
+
+	// Without colors there are no color properties
+	plain := testBox(1, 1, 1)
+	var buf bytes.Buffer
+	if err := plain.ToPLY(&buf, PLYASCII); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if strings.Contains(buf.String(), "red") {
+		t.Errorf("got colors in %q", buf.String())
+	}
+
+	if err := plain.ToPLY(&buf, PLYEncoding(9)); err == nil {
+		t.Errorf("got no error for an unknown encoding")
+	}
+}
+func TestSolid_ToPLYFromBinary(t *testing.T) {
+	var bin bytes.Buffer
+	box := testBox(1, 1, 1)
+	if err := box.ToBinary(&bin); err != nil {
+		t.Fatalf("could not write binary: %v", err)
+	}
+	s, err := From(&bin)
+	if err != nil {
+		t.Fatalf("could not read binary: %v", err)
+	}
+
+	// The header read keeps the NUL padding of the binary header
+	var buf bytes.Buffer
+	if err := s.ToPLY(&buf, PLYASCII); err != nil {
+		t.Fatalf("could not write: %v", err)
+	}
+	if out := buf.String(); strings.ContainsRune(out, 0) || !strings.Contains(out, "\ncomment box\n") {
+		t.Errorf("got header %q; want comment box without NULs", out[:min(len(out), 120)])
+	}
+}